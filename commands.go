@@ -0,0 +1,292 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"simjack/config"
+	"simjack/engine"
+)
+
+// Alt komutlar: "simjack <komut> [flag'ler]" şeklinde çağrılır
+var commands = map[string]func(args []string) error{
+	"indices":  runIndicesCommand,
+	"validate": runValidateCommand,
+	"risk":     runRiskCommand,
+	"chart":    runChartCommand,
+	"diff":     runDiffCommand,
+}
+
+func loadConfigFile(path string) (config.SimulationConfig, error) {
+	var cfg config.SimulationConfig
+	file, err := os.Open(path)
+	if err != nil {
+		return cfg, fmt.Errorf("failed to open config file: %w", err)
+	}
+	defer file.Close()
+	if err := json.NewDecoder(file).Decode(&cfg); err != nil {
+		return cfg, fmt.Errorf("failed to parse config file: %w", err)
+	}
+	return cfg, nil
+}
+
+// writeJSONOutput, sonucu verilen dosyaya ya da dosya yoksa stdout'a yazar
+func writeJSONOutput(path string, v interface{}) error {
+	out, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	out = append(out, '\n')
+	if path == "" {
+		_, err = os.Stdout.Write(out)
+		return err
+	}
+	return os.WriteFile(path, out, 0644)
+}
+
+func runIndicesCommand(args []string) error {
+	fs := flag.NewFlagSet("indices", flag.ExitOnError)
+	configPath := fs.String("config", "config.json", "Path to simulation config JSON file (table rules)")
+	strategyDir := fs.String("strategies", "strategies", "Directory containing strategy JSON files")
+	strategyName := fs.String("strategy", "", "Strategy name whose actions will be used as the base chart")
+	trials := fs.Int("trials", 2000, "Hands played per action, key and true count")
+	minCount := fs.Int("min-count", -6, "Lowest true count to evaluate")
+	maxCount := fs.Int("max-count", 10, "Highest true count to evaluate")
+	decksRemaining := fs.Float64("decks-remaining", 0, "Decks left in the shoe at decision time (default: half the shoe)")
+	outPath := fs.String("out", "", "Write the deviations block to this file instead of stdout")
+	fs.Parse(args)
+
+	if *strategyName == "" {
+		return fmt.Errorf("-strategy is required")
+	}
+	cfg, err := loadConfigFile(*configPath)
+	if err != nil {
+		return err
+	}
+	if err := engine.SetStrategyDirectory(*strategyDir); err != nil {
+		return err
+	}
+	data, err := engine.ReadCountingStrategyFile(*strategyName)
+	if err != nil {
+		return err
+	}
+
+	opts := engine.IndexGenOptionsFromConfig(cfg)
+	opts.Trials = *trials
+	opts.MinCount = *minCount
+	opts.MaxCount = *maxCount
+	if *decksRemaining > 0 {
+		opts.DecksRemaining = *decksRemaining
+	}
+	if opts.NumDecks < 1 || opts.Trials < 1 || opts.MinCount > opts.MaxCount {
+		return fmt.Errorf("invalid options: num_decks=%d trials=%d count range=[%d,%d]", opts.NumDecks, opts.Trials, opts.MinCount, opts.MaxCount)
+	}
+
+	deviations, estimates := engine.GenerateDeviations(data, opts)
+	// Index'lerin tahmin hatası JSON çıktısını bozmamak için stderr'e yazılır
+	keys := make([]string, 0, len(deviations))
+	for key := range deviations {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		dev, est := deviations[key], estimates[key]
+		fmt.Fprintf(os.Stderr, "%-16s %-9s at TC >= %-3d gain %+.4f ± %.4f\n", key, dev.Action, dev.AtCount, est.Gain, est.StdErr)
+	}
+	return writeJSONOutput(*outPath, map[string]interface{}{"deviations": deviations})
+}
+
+func runValidateCommand(args []string) error {
+	fs := flag.NewFlagSet("validate", flag.ExitOnError)
+	strategyDir := fs.String("strategies", "strategies", "Directory containing strategy JSON files")
+	strategyName := fs.String("strategy", "", "Strategy name to validate (default: every .json file in the directory)")
+	showWarnings := fs.Bool("warnings", true, "Also report warnings (missing/unreachable keys, ramp order...)")
+	asJSON := fs.Bool("json", false, "Print issues as JSON")
+	fs.Parse(args)
+
+	if err := engine.SetStrategyDirectory(*strategyDir); err != nil {
+		return err
+	}
+	names := []string{}
+	if *strategyName != "" {
+		names = append(names, *strategyName)
+	} else {
+		files, err := filepath.Glob(filepath.Join(*strategyDir, "*.json"))
+		if err != nil {
+			return err
+		}
+		for _, f := range files {
+			names = append(names, strings.TrimSuffix(filepath.Base(f), ".json"))
+		}
+		sort.Strings(names)
+	}
+
+	report := map[string][]engine.ValidationIssue{}
+	failed := false
+	for _, name := range names {
+		raw, err := os.ReadFile(engine.StrategyFilePath(name))
+		if err != nil {
+			return fmt.Errorf("failed to load strategy file: %w", err)
+		}
+		issues, err := engine.ValidateCountingStrategyJSON(name, raw)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		if engine.HasValidationErrors(issues) {
+			failed = true
+		}
+		if !*showWarnings {
+			filtered := []engine.ValidationIssue{}
+			for _, i := range issues {
+				if i.Severity == engine.SeverityError {
+					filtered = append(filtered, i)
+				}
+			}
+			issues = filtered
+		}
+		report[name] = issues
+	}
+
+	if *asJSON {
+		if err := writeJSONOutput("", report); err != nil {
+			return err
+		}
+	} else {
+		for _, name := range names {
+			fmt.Printf("%s: %d issue(s)\n", name, len(report[name]))
+			for _, i := range report[name] {
+				fmt.Println("  " + i.String())
+			}
+		}
+	}
+
+	if failed {
+		return fmt.Errorf("strategy validation found errors")
+	}
+	return nil
+}
+
+func runRiskCommand(args []string) error {
+	fs := flag.NewFlagSet("risk", flag.ExitOnError)
+	configPath := fs.String("config", "config.json", "Path to simulation config JSON file")
+	strategyDir := fs.String("strategies", "", "Directory containing strategy JSON files (default: strategy_directory from config)")
+	trials := fs.Int("trials", 1000, "Number of independent bankroll trials")
+	sampleEvery := fs.Int("sample-every", 100, "Sample bankroll percentiles every N rounds")
+	seed := fs.Int64("seed", 0, "Base random seed for reproducible trials (0: random)")
+	curvesPath := fs.String("out", "risk_curves.csv", "CSV file for percentile bankroll curves")
+	summaryPath := fs.String("summary", "", "Write risk summary JSON to this file instead of stdout")
+	fs.Parse(args)
+
+	cfg, err := loadConfigFile(*configPath)
+	if err != nil {
+		return err
+	}
+	if *strategyDir != "" {
+		cfg.StrategyDirectory = *strategyDir
+	}
+	if err := engine.SetStrategyDirectory(cfg.StrategyDirectory); err != nil {
+		return err
+	}
+
+	results, err := engine.RunRiskAnalysis(cfg, nil, engine.RiskOptions{
+		Trials:      *trials,
+		SampleEvery: *sampleEvery,
+		Seed:        *seed,
+	})
+	if err != nil {
+		return err
+	}
+	if err := engine.WriteRiskCurvesCSV(*curvesPath, results); err != nil {
+		return fmt.Errorf("failed to write curves: %w", err)
+	}
+	return writeJSONOutput(*summaryPath, results)
+}
+
+// runChartCommand, stratejiyi terminalde tablo olarak gösterir ya da tablo (CSV/grid) dosyalarına
+// aktarır ve tablolardan strateji dosyası üretir
+func runChartCommand(args []string) error {
+	fs := flag.NewFlagSet("chart", flag.ExitOnError)
+	strategyDir := fs.String("strategies", "strategies", "Directory containing strategy JSON files")
+	strategyName := fs.String("strategy", "", "Strategy to render (or export with -export)")
+	exportPath := fs.String("export", "", "Write the strategy's actions as a chart to this file (.csv or grid text)")
+	importPath := fs.String("import", "", "Read a chart (.csv or grid text) and write a strategy JSON")
+	outPath := fs.String("out", "", "Strategy JSON written by -import (default: stdout)")
+	extends := fs.String("extends", "", "With -import: base strategy the imported chart overrides")
+	fallback := fs.String("fallback", "stand", "With -import: fallback action when -extends is not set")
+	fs.Parse(args)
+
+	if *importPath != "" {
+		file, err := os.Open(*importPath)
+		if err != nil {
+			return fmt.Errorf("failed to open chart: %w", err)
+		}
+		defer file.Close()
+		actions, err := engine.ImportChart(file, engine.ChartFormatFor(*importPath))
+		if err != nil {
+			return err
+		}
+		// Yalnızca tablodan gelen alanlar yazılır; extends ile temelin diğer alanları korunur
+		out := map[string]interface{}{"actions": actions}
+		if *extends != "" {
+			out["extends"] = *extends
+		} else {
+			out["fallback"] = *fallback
+		}
+		return writeJSONOutput(*outPath, out)
+	}
+
+	if *strategyName == "" {
+		return fmt.Errorf("-strategy or -import is required")
+	}
+	if err := engine.SetStrategyDirectory(*strategyDir); err != nil {
+		return err
+	}
+	data, err := engine.ReadCountingStrategyFile(*strategyName)
+	if err != nil {
+		return err
+	}
+	if *exportPath != "" {
+		file, err := os.Create(*exportPath)
+		if err != nil {
+			return fmt.Errorf("failed to create chart: %w", err)
+		}
+		defer file.Close()
+		return engine.ExportChart(file, data.Actions, engine.ChartFormatFor(*exportPath))
+	}
+	return engine.RenderChart(os.Stdout, *strategyName, data)
+}
+
+// runDiffCommand, iki stratejinin aksiyon, sapma ve bahis rampası farklarını yazar
+func runDiffCommand(args []string) error {
+	fs := flag.NewFlagSet("diff", flag.ExitOnError)
+	strategyDir := fs.String("strategies", "strategies", "Directory containing strategy JSON files")
+	asJSON := fs.Bool("json", false, "Print differences as JSON")
+	fs.Parse(args)
+
+	if fs.NArg() != 2 {
+		return fmt.Errorf("usage: simjack diff [flags] <strategy-a> <strategy-b>")
+	}
+	if err := engine.SetStrategyDirectory(*strategyDir); err != nil {
+		return err
+	}
+	nameA, nameB := fs.Arg(0), fs.Arg(1)
+	a, err := engine.ReadCountingStrategyFile(nameA)
+	if err != nil {
+		return err
+	}
+	b, err := engine.ReadCountingStrategyFile(nameB)
+	if err != nil {
+		return err
+	}
+
+	diff := engine.DiffStrategies(a, b)
+	if *asJSON {
+		return writeJSONOutput("", diff)
+	}
+	return engine.RenderStrategyDiff(os.Stdout, nameA, nameB, diff)
+}
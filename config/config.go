@@ -5,6 +5,7 @@ type SimulationConfig struct {
 	RoundCount            int            `json:"round_count"`
 	HitOnSoft17           bool           `json:"hit_on_soft_17"`
 	AllowDoubleAfterSplit bool           `json:"allow_double_after_split"`
+	DoubleFirstTwoOnly    bool           `json:"double_first_two_cards_only"` // double yalnızca ilk iki kartla (varsayılan: her kart sayısında)
 	DealerTakesHoleCard   bool           `json:"dealer_takes_hole_card"`
 	ForcedCards           []string       `json:"forced_cards"`
 	StrategyDirectory     string         `json:"strategy_directory"`
@@ -14,6 +15,12 @@ type SimulationConfig struct {
 	SurrenderAgainstAce   bool           `json:"surrender_against_ace"` 
 	MinBet                float64        `json:"min_bet"` 
 	MaxBet                float64        `json:"max_bet"`
+	RoundsPerHour         float64        `json:"rounds_per_hour"` // saatlik kazanç istatistikleri için masa hızı (varsayılan 60)
+	CSM                   *CSMConfig     `json:"csm"`             // verilirse shoe yerine sürekli karıştırma makinesi kullanılır
+	InfiniteDeck          bool           `json:"infinite_deck"`   // her kart iadeli olarak bağımsız çekilir (csm yok sayılır)
+	Shuffle               *ShuffleConfig `json:"shuffle"`         // verilmezse her shoe kusursuz rastgele karıştırılır
+	BurnCards             int            `json:"burn_cards"`      // her karıştırmadan sonra yakılan kart sayısı
+	BurnFaceUp            bool           `json:"burn_face_up"`    // yakılan kartlar açık mı (oyuncular görür ve sayar)
 	Players               []PlayerConfig `json:"players"`
 }
 
@@ -25,6 +32,34 @@ type PlayerConfig struct {
 	Owner          string            `json:"owner"`
 	Boxes          []BoxAssignment   `json:"boxes"`
 	AcceptInsurance  bool            `json:"accept_insurance"` 
+	Wonging        *WongingConfig    `json:"wonging"`
+	HoleCardProbability float64      `json:"hole_card_probability"` // dealer'ın hole card'ını bu oyuncuya gösterme olasılığı (round başına)
+}
+
+// CSM (continuous shuffling machine): her round'un kartları makineye geri döner ve rastgele
+// konumlara karışır; cut card ve shoe değişimi olmaz. ReturnDelayRounds, makinenin tuttuğu
+// tamponu modeller: kartlar ancak bu kadar round sonra makineye geri girer.
+type CSMConfig struct {
+	ReturnDelayRounds int `json:"return_delay_rounds"`
+}
+
+// ShuffleConfig, shoe'nun gerçekçi (kusursuz olmayan) karıştırma modelidir. İlk shoe rastgele
+// karıştırılır; sonraki shoe'lar önceki shoe'nun discard sırasından üretilir.
+type ShuffleConfig struct {
+	Model        string  `json:"model"`         // random (varsayılan), riffle, zone
+	Riffles      int     `json:"riffles"`       // riffle sayısı (varsayılan 3)
+	Clumping     float64 `json:"clumping"`      // riffle'da birlikte düşen ortalama kart sayısı (1 = GSR)
+	StripCuts    int     `json:"strip_cuts"`    // riffle'lardan sonraki strip cut sayısı
+	StripPackets int     `json:"strip_packets"` // strip cut başına paket sayısı (varsayılan 5)
+	Zones        int     `json:"zones"`         // zone modelinde bölge sayısı (varsayılan 2)
+}
+
+// Wonging (back-counting): oyuncu masayı izleyip count'u takip eder, true count EnterAt'e
+// ulaşınca box'larına oturur, ExitBelow'un altına düşünce ya da karıştırmada kalkar.
+type WongingConfig struct {
+	EnterAt          float64 `json:"enter_at"`
+	ExitBelow        float64 `json:"exit_below"`
+	StayAfterShuffle bool    `json:"stay_after_shuffle"`
 }
 
 
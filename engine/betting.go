@@ -0,0 +1,147 @@
+package engine
+
+import (
+	"fmt"
+	"math"
+)
+
+// Bahis modları
+const (
+	BetModeRamp            = "ramp"             // box bahsi x bet_ramp çarpanı (varsayılan)
+	BetModeKelly           = "kelly"            // tahmini avantaja göre (kesirli) Kelly
+	BetModeBankrollPercent = "bankroll_percent" // bakiyenin yüzdesi x bet_ramp çarpanı
+)
+
+// Bakiye belirli bir eşiği geçtiğinde temel birimin yeniden boyutlandırılması
+type UnitResizeTier struct {
+	MinBalance     float64 `json:"min_balance"`
+	UnitMultiplier float64 `json:"unit_multiplier"`
+}
+
+// BettingConfig, strateji dosyasındaki "betting" bölümüdür. Boş bırakılırsa klasik rampa kullanılır.
+type BettingConfig struct {
+	Mode string `json:"mode"`
+
+	// Kelly: avantaj(TC) = BaseAdvantage + AdvantagePerCount * TC, bahis = bakiye * KellyFraction * avantaj / Variance.
+	// Yazılmayan alanlar nil ile ayırt edilir: BaseAdvantage ve AdvantagePerCount için 0 geçerli bir
+	// değerdir, KellyFraction için 0 ise doğrulamada hata olarak raporlanır.
+	KellyFraction     *float64 `json:"kelly_fraction"`
+	BaseAdvantage     *float64 `json:"base_advantage"`
+	AdvantagePerCount *float64 `json:"advantage_per_count"`
+	Variance          float64  `json:"variance"`
+
+	// bankroll_percent: temel birim = bakiye * BankrollPercent
+	BankrollPercent float64 `json:"bankroll_percent"`
+
+	// ramp modunda temel birimi bakiye eşiklerine göre büyütür/küçültür
+	UnitResizing []UnitResizeTier `json:"unit_resizing"`
+
+	// Bahsi bu değerin katına aşağı yuvarlar (fiş büyüklüğü), 0 ise yuvarlama yapılmaz
+	RoundTo float64 `json:"round_to"`
+}
+
+// Kelly için literatürdeki tipik varsayılanlar
+const (
+	defaultKellyFraction     = 1.0
+	defaultBaseAdvantage     = -0.005
+	defaultAdvantagePerCount = 0.005
+	defaultVariance          = 1.33
+)
+
+func (b *BettingConfig) mode() string {
+	if b == nil || b.Mode == "" {
+		return BetModeRamp
+	}
+	return b.Mode
+}
+
+// Advantage, verilen true count için tahmini oyuncu avantajıdır
+func (b *BettingConfig) Advantage(trueCount float64) float64 {
+	base, perCount := defaultBaseAdvantage, defaultAdvantagePerCount
+	if b.BaseAdvantage != nil {
+		base = *b.BaseAdvantage
+	}
+	if b.AdvantagePerCount != nil {
+		perCount = *b.AdvantagePerCount
+	}
+	return base + perCount*trueCount
+}
+
+// kellyBet, avantaj yoksa box'ın bekleme bahsini (config'teki main_bet) döndürür
+func (b *BettingConfig) kellyBet(base, bankroll, trueCount float64) float64 {
+	adv := b.Advantage(trueCount)
+	if adv <= 0 {
+		return base
+	}
+	fraction, variance := defaultKellyFraction, defaultVariance
+	if b.KellyFraction != nil {
+		fraction = *b.KellyFraction
+	}
+	if b.Variance > 0 {
+		variance = b.Variance
+	}
+	return bankroll * fraction * adv / variance
+}
+
+// resizeUnit, bakiyenin geçtiği en yüksek eşiğin çarpanını temel birime uygular
+func (b *BettingConfig) resizeUnit(base, bankroll float64) float64 {
+	if b == nil {
+		return base
+	}
+	for i := len(b.UnitResizing) - 1; i >= 0; i-- {
+		if bankroll >= b.UnitResizing[i].MinBalance {
+			return base * b.UnitResizing[i].UnitMultiplier
+		}
+	}
+	return base
+}
+
+func (b *BettingConfig) round(bet float64) float64 {
+	if b == nil || b.RoundTo <= 0 {
+		return bet
+	}
+	return math.Floor(bet/b.RoundTo) * b.RoundTo
+}
+
+// validateBetting, betting bölümündeki hataları ValidateCountingStrategy raporuna ekler
+func validateBetting(b *BettingConfig) []ValidationIssue {
+	issues := []ValidationIssue{}
+	if b == nil {
+		return issues
+	}
+	add := func(severity, format string, args ...interface{}) {
+		issues = append(issues, ValidationIssue{Severity: severity, Section: "betting", Message: fmt.Sprintf(format, args...)})
+	}
+	switch b.mode() {
+	case BetModeRamp:
+	case BetModeKelly:
+		if b.KellyFraction != nil && *b.KellyFraction <= 0 {
+			add(SeverityError, "kelly_fraction must be positive, got %.2f", *b.KellyFraction)
+		} else if b.KellyFraction != nil && *b.KellyFraction > 1 {
+			add(SeverityWarning, "kelly_fraction %.2f is above 1, over-betting Kelly increases risk of ruin", *b.KellyFraction)
+		}
+		if b.Variance < 0 {
+			add(SeverityError, "variance must be positive, got %.2f", b.Variance)
+		}
+	case BetModeBankrollPercent:
+		if b.BankrollPercent <= 0 || b.BankrollPercent > 1 {
+			add(SeverityError, "bankroll_percent must be in (0, 1], got %.4f", b.BankrollPercent)
+		}
+	default:
+		add(SeverityError, "unknown betting mode %q", b.Mode)
+	}
+	for i := 1; i < len(b.UnitResizing); i++ {
+		if b.UnitResizing[i].MinBalance <= b.UnitResizing[i-1].MinBalance {
+			add(SeverityError, "unit_resizing tier %d min_balance is not greater than previous tier's", i)
+		}
+	}
+	for i, t := range b.UnitResizing {
+		if t.UnitMultiplier <= 0 {
+			add(SeverityError, "unit_resizing tier %d unit_multiplier must be positive", i)
+		}
+	}
+	if b.RoundTo < 0 {
+		add(SeverityError, "round_to must not be negative")
+	}
+	return issues
+}
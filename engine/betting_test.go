@@ -0,0 +1,60 @@
+package engine
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+func TestBettingAdvantage(t *testing.T) {
+	tests := []struct {
+		betting string
+		tc      float64
+		want    float64
+	}{
+		{`{"mode": "kelly"}`, 2, 0.005},
+		{`{"mode": "kelly", "base_advantage": 0}`, 2, 0.01},
+		{`{"mode": "kelly", "base_advantage": 0.002, "advantage_per_count": 0.004}`, 1, 0.006},
+		{`{"mode": "kelly", "base_advantage": 0.01, "advantage_per_count": 0}`, 4, 0.01},
+	}
+	for _, tt := range tests {
+		var b BettingConfig
+		if err := json.Unmarshal([]byte(tt.betting), &b); err != nil {
+			t.Fatal(err)
+		}
+		if got := b.Advantage(tt.tc); math.Abs(got-tt.want) > 1e-12 {
+			t.Errorf("%s: Advantage(%v) = %v, want %v", tt.betting, tt.tc, got, tt.want)
+		}
+	}
+}
+
+func TestKellyBetFraction(t *testing.T) {
+	tests := []struct {
+		betting   string
+		want      float64
+		wantError bool
+	}{
+		{`{"mode": "kelly", "base_advantage": 0.0133}`, 100, false},
+		{`{"mode": "kelly", "base_advantage": 0.0133, "kelly_fraction": 0.5}`, 50, false},
+		{`{"mode": "kelly", "base_advantage": 0.0133, "kelly_fraction": 0}`, 0, true},
+	}
+	for _, tt := range tests {
+		var b BettingConfig
+		if err := json.Unmarshal([]byte(tt.betting), &b); err != nil {
+			t.Fatal(err)
+		}
+		hasError := false
+		for _, issue := range validateBetting(&b) {
+			hasError = hasError || issue.Severity == SeverityError
+		}
+		if hasError != tt.wantError {
+			t.Errorf("%s: validation error %v, want %v", tt.betting, hasError, tt.wantError)
+		}
+		if tt.wantError {
+			continue
+		}
+		if got := b.kellyBet(10, 10000, 0); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("%s: kellyBet = %v, want %v", tt.betting, got, tt.want)
+		}
+	}
+}
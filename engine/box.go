@@ -26,6 +26,12 @@ type Box struct {
 	InsuranceBet    float64
 	InsuranceResult string
 	InsurancePayout float64
+	Progression      BettingSystem // strateji progresyon tanımlıyorsa box'a özel durum
+	ProgressionUnit  float64       // bu round'da progresyon çarpanının uygulandığı birim bahis
+	ProgressionState string        // bu round'un bahsi belirlenirken sistemin durumu (log için)
+	SittingOut       bool          // spread düşük count'ta bu box'ı boş bırakıyor
+	SpreadFraction   float64       // spread aktifken el başına bahis oranı (0: uygulanmaz)
+	Borrowed         bool          // spread için bu round'a özel ödünç alınmış boş box
 }
 
 func (b *Box) AddHand(h *Hand) {
@@ -53,6 +59,8 @@ func (b *Box) Reset() {
 	b.InsuranceBet = 0
 	b.InsuranceResult = "none"
 	b.InsurancePayout = 0
+	b.ProgressionUnit = 0
+	b.ProgressionState = ""
 }
 
 func NewBoxWithConfig(cfg config.BoxAssignment, player *Player) *Box {
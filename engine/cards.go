@@ -3,8 +3,11 @@ package engine
 import (
 	"fmt"
 	"math/rand"
+	"strconv"
 	"strings"
 	"time"
+
+	"simjack/config"
 )
 
 func init() {
@@ -27,8 +30,7 @@ func (c Card) Value() int {
 	case "K", "Q", "J":
 		return 10
 	default:
-		var v int
-		fmt.Sscanf(c.Rank, "%d", &v)
+		v, _ := strconv.Atoi(c.Rank) // her elde defalarca çağrılır, fmt.Sscanf burada pahalıdır
 		return v
 	}
 }
@@ -46,6 +48,29 @@ type Deck struct {
 	ForcedCards          []Card
 	RunningCount         int
 	RealCountTillCutCard int
+	CSM                  bool // sürekli karıştırma makinesi: cut card yok, kartlar round sonunda geri döner
+	CSMReturnDelay       int  // kartların makineye dönmeden önce bekleyeceği round sayısı
+	Infinite             bool // sonsuz deste: her kart iadeli çekilir, count tutulmaz
+	Shuffle              *config.ShuffleConfig
+	discardOrder         []Card // bu shoe'da dağıtılan kartlar, dağıtılma sırasıyla
+	BurnCards            int    // her karıştırmadan sonra yakılan kart sayısı
+	BurnFaceUp           bool
+	burned               []Card
+	roundDiscards        []Card
+	pendingDiscards      [][]Card
+	observers            []DeckObserver
+}
+
+// DeckObserver, shoe'dan çekilen her kartı ve her karıştırmayı izleyen bileşenlerdir
+// (ör. kendi running count'unu tutan ve hata yapabilen bir sayıcı).
+type DeckObserver interface {
+	OnCardDealt(c Card)
+	OnShuffle()
+	OnCardsReturned(cards []Card) // CSM: görülmüş kartlar karıştırılmadan shoe'ya geri döndü
+}
+
+func (d *Deck) AddObserver(o DeckObserver) {
+	d.observers = append(d.observers, o)
 }
 
 func NewDeck(numDecks int, forced []Card) *Deck {
@@ -57,7 +82,24 @@ func NewDeck(numDecks int, forced []Card) *Deck {
 	return d
 }
 
+// NewInfiniteDeck, analitik sonsuz deste tablolarıyla karşılaştırma için sonsuz deste oluşturur.
+// Zorunlu kartlar önce dağıtılır; sonrasında her kart 13 rank arasından eşit olasılıkla çekilir.
+// Cut card ve shoe sınırı yoktur, running count hep 0 kalır.
+func NewInfiniteDeck(numDecks int, forced []Card) *Deck {
+	return &Deck{
+		NumDecks:    numDecks,
+		ForcedCards: forced,
+		Infinite:    true,
+		Cards:       append([]Card{}, forced...),
+	}
+}
+
 func (d *Deck) SetupShoe() {
+	// Önceki shoe: discard tray (dağıtılma sırasıyla) ve altında dağıtılmamış kartlar
+	previousDiscards := d.discardOrder
+	previous := append(append(append([]Card{}, d.burned...), d.discardOrder...), d.Cards...)
+	var newPositions []int // discard'ların yeni shoe'daki konumları, karıştırma izlenebiliyorsa
+
 	full := []Card{}
 	for i := 0; i < d.NumDecks; i++ {
 		for _, suit := range Suits {
@@ -110,6 +152,17 @@ func (d *Deck) SetupShoe() {
 
 		d.Cards = append([]Card{}, d.ForcedCards...)
 		d.Cards = append(d.Cards, remaining...)
+	} else if !shuffleIsRandom(d.Shuffle) && len(previous) == len(full) {
+		order := applyShuffleModel(d.Shuffle, len(previous))
+		d.Cards = make([]Card, len(order))
+		newPositions = make([]int, len(previousDiscards))
+		for pos, from := range order {
+			d.Cards[pos] = previous[from]
+			// previous yakılan kartlarla başlar; discard'lar onlardan sonra gelir
+			if i := from - len(d.burned); i >= 0 && i < len(previousDiscards) {
+				newPositions[i] = pos
+			}
+		}
 	} else {
 		d.Cards = full
 	}
@@ -120,30 +173,121 @@ func (d *Deck) SetupShoe() {
 	d.NeedsNewDeck = false
 	d.DrawnThisShoe = 0
 	d.RunningCount = 0
+	d.roundDiscards = nil
+	d.pendingDiscards = nil
+	d.discardOrder = nil
+	d.burned = nil
 
 	d.RealCountTillCutCard = 0
 	for i := 0; i < d.CutCardPosition; i++ {
 		d.adjustRealCountTillCutCard(d.Cards[i])
 	}
+
+	for _, o := range d.observers {
+		if so, ok := o.(ShuffleObserver); ok {
+			so.OnNewShoe(previousDiscards, newPositions)
+		}
+		o.OnShuffle()
+	}
+	d.burn()
+}
+
+// burn, shoe'nun başından BurnCards kadar kartı yakar. Açık yakılan kartlar dağıtılmış gibi
+// sayılır ve gözlemcilere bildirilir; kapalı yakılanlar kimse tarafından görülmez.
+// Zorunlu kartlar varsa yakma yapılmaz.
+func (d *Deck) burn() {
+	if len(d.ForcedCards) > 0 {
+		return
+	}
+	for i := 0; i < d.BurnCards && len(d.Cards) > 0; i++ {
+		c := d.Cards[0]
+		d.Cards = d.Cards[1:]
+		d.DrawnThisShoe++
+		d.burned = append(d.burned, c)
+		if d.BurnFaceUp {
+			d.adjustRunningCount(c)
+			for _, o := range d.observers {
+				o.OnCardDealt(c)
+			}
+		}
+	}
+}
+
+// VisibleBurned, bu shoe'da açık yakılan kartları döndürür
+func (d *Deck) VisibleBurned() []Card {
+	if !d.BurnFaceUp {
+		return nil
+	}
+	return d.burned
+}
+
+// PreviousRoundCards, bu shoe'da önceki round'larda dağıtılıp hâlâ masa dışında görülebilen
+// kartları döndürür (CSM'de makineye henüz dönmemiş kartlar).
+func (d *Deck) PreviousRoundCards() []Card {
+	if d.Infinite {
+		return nil
+	}
+	if d.CSM {
+		cards := []Card{}
+		for _, batch := range d.pendingDiscards {
+			cards = append(cards, batch...)
+		}
+		return cards
+	}
+	return d.discardOrder[:len(d.discardOrder)-d.DrawnThisRound]
 }
 
 func (d *Deck) DealCard() (Card, error) {
+	if d.Infinite {
+		d.DrawnThisRound++
+		d.DrawnThisShoe++
+		if len(d.Cards) > 0 {
+			c := d.Cards[0]
+			d.Cards = d.Cards[1:]
+			return c, nil
+		}
+		return Card{Rank: Ranks[rand.Intn(len(Ranks))], Suit: Suits[rand.Intn(len(Suits))]}, nil
+	}
+	if d.CSM && len(d.Cards) == 0 && len(d.pendingDiscards) > 0 {
+		// Tampon makineyi boşalttı; en eski kartlar beklemeden geri döner
+		d.returnDiscards(d.pendingDiscards[0])
+		d.pendingDiscards = d.pendingDiscards[1:]
+	}
 	if len(d.Cards) == 0 {
 		return Card{}, fmt.Errorf("no cards left in deck")
 	}
 	d.DrawnThisRound++
 	d.DrawnThisShoe++
-	if len(d.Cards) <= d.CutCardPosition {
+	if !d.CSM && len(d.Cards) <= d.CutCardPosition {
 		d.NeedsNewDeck = true
 	}
 	c := d.Cards[0]
 	d.Cards = d.Cards[1:]
 	d.adjustRunningCount(c)
 	d.adjustRealCountTillCutCard(c)
+	if d.CSM {
+		d.roundDiscards = append(d.roundDiscards, c)
+	} else {
+		d.discardOrder = append(d.discardOrder, c)
+	}
+	for _, o := range d.observers {
+		o.OnCardDealt(c)
+	}
 	return c, nil
 }
 
+// ShuffleIfNeeded, round sonunda çağrılır. CSM modunda shoe hiç değişmez; bunun yerine
+// round'un kartları tampona alınır ve gecikmesi dolan kartlar makineye geri döner.
 func (d *Deck) ShuffleIfNeeded() bool {
+	if d.CSM {
+		d.pendingDiscards = append(d.pendingDiscards, d.roundDiscards)
+		d.roundDiscards = nil
+		for len(d.pendingDiscards) > d.CSMReturnDelay {
+			d.returnDiscards(d.pendingDiscards[0])
+			d.pendingDiscards = d.pendingDiscards[1:]
+		}
+		return false
+	}
 	if d.NeedsNewDeck {
 		d.SetupShoe()
 		return true
@@ -151,6 +295,21 @@ func (d *Deck) ShuffleIfNeeded() bool {
 	return false
 }
 
+// returnDiscards, kartları makinedeki rastgele konumlara yerleştirir. Kartlar artık shoe'da
+// olduğundan running count'tan düşülür; böylece count yalnızca makine dışındaki kartları yansıtır.
+func (d *Deck) returnDiscards(cards []Card) {
+	for _, c := range cards {
+		i := rand.Intn(len(d.Cards) + 1)
+		d.Cards = append(d.Cards, Card{})
+		copy(d.Cards[i+1:], d.Cards[i:])
+		d.Cards[i] = c
+		d.RunningCount -= getHiLoValue(c)
+	}
+	for _, o := range d.observers {
+		o.OnCardsReturned(cards)
+	}
+}
+
 func (d *Deck) ResetRoundCounter() {
 	d.DrawnThisRound = 0
 }
@@ -189,3 +348,12 @@ func (d *Deck) adjustRealCountTillCutCard(c Card) {
 func (d *Deck) GetRunningCount() int {
 	return d.RunningCount
 }
+
+// TrueCount, running count'un kalan deste sayısına bölünmüş halidir
+func (d *Deck) TrueCount() float64 {
+	remainingDecks := float64(len(d.Cards)) / 52.0
+	if remainingDecks == 0 {
+		return 0
+	}
+	return float64(d.RunningCount) / remainingDecks
+}
@@ -0,0 +1,451 @@
+package engine
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Strateji tabloları (chart): satırlar oyuncu eli (hard_16, soft_18, pair_8), sütunlar dealer
+// açık kartıdır (DealerKeys). Hücreler standart tablo kodlarıyla yazılır.
+
+// ChartCode, tablo kodunun karşılığı olan aksiyon listesidir
+type ChartCode struct {
+	Code    string
+	Actions []string
+}
+
+// ChartCodes, desteklenen tablo kodları; dışa aktarımda aynı listeye sahip ilk kod kullanılır
+var ChartCodes = []ChartCode{
+	{"H", []string{"hit"}},
+	{"S", []string{"stand"}},
+	{"D", []string{"double", "hit"}},
+	{"Dh", []string{"double", "hit"}},
+	{"Ds", []string{"double", "stand"}},
+	{"P", []string{"split"}},
+	{"Ph", []string{"split", "hit"}},
+	{"Ps", []string{"split", "stand"}},
+	{"Pd", []string{"split", "double", "hit"}},
+	{"Rh", []string{"surrender", "hit"}},
+	{"Rs", []string{"surrender", "stand"}},
+	{"Rp", []string{"surrender", "split"}},
+}
+
+// Tablo dosya biçimleri
+const (
+	ChartFormatCSV  = "csv"  // virgülle ayrılmış, boş hücre = anahtar yok
+	ChartFormatGrid = "grid" // boşlukla hizalanmış, boş hücre "-"
+)
+
+// ChartFormatFor, dosya uzantısına göre tablo biçimini seçer (.csv dışındakiler grid)
+func ChartFormatFor(path string) string {
+	if strings.HasSuffix(strings.ToLower(path), ".csv") {
+		return ChartFormatCSV
+	}
+	return ChartFormatGrid
+}
+
+// ParseChartCell, bir hücreyi aksiyon listesine çevirir. Kodların yanında "split/stand" gibi
+// "/" ile ayrılmış açık aksiyon listeleri de kabul edilir. Boş hücre (ya da "-") nil döndürür.
+func ParseChartCell(cell string) ([]string, error) {
+	cell = strings.TrimSpace(cell)
+	if cell == "" || cell == "-" {
+		return nil, nil
+	}
+	for _, c := range ChartCodes {
+		if strings.EqualFold(c.Code, cell) {
+			return append([]string{}, c.Actions...), nil
+		}
+	}
+	actions := []string{}
+	for _, a := range strings.Split(cell, "/") {
+		a = strings.ToLower(strings.TrimSpace(a))
+		if !isKnownAction(a) {
+			return nil, fmt.Errorf("unknown chart code %q", cell)
+		}
+		actions = append(actions, a)
+	}
+	return actions, nil
+}
+
+// ChartCell, aksiyon listesini tablo koduna çevirir; karşılığı olmayan listeler "/" ile yazılır
+func ChartCell(actions []string) string {
+	if len(actions) == 0 {
+		return "-"
+	}
+	for _, c := range ChartCodes {
+		if equalActions(c.Actions, actions) {
+			return c.Code
+		}
+	}
+	return strings.Join(actions, "/")
+}
+
+func equalActions(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// tenPairRanks, tabloda pair_10 satırıyla birlikte yazılan onluk çiftleridir
+var tenPairRanks = []string{"J", "Q", "K"}
+
+// ChartRows, tabloda gösterilecek satırları (anahtarın oyuncu kısmı) hard, soft, pair sırasıyla
+// döndürür: engine'in sorduğu tüm satırlar ve actions'ta bulunan diğer geçerli satırlar. J/Q/K
+// çiftleri pair_10'dan farklı değilse ayrı satır olarak gösterilmez.
+func ChartRows(actions map[string][]string) []string {
+	seen := map[string]StrategyKey{}
+	add := func(key string) {
+		k, err := ParseStrategyKey(key)
+		if err != nil {
+			return
+		}
+		row := strings.TrimSuffix(key, "_vs_"+k.Dealer)
+		seen[row] = k
+	}
+	for _, key := range ReachableStrategyKeys() {
+		add(key)
+	}
+	for key := range actions {
+		add(key)
+	}
+	for _, r := range tenPairRanks {
+		row := KeyKindPair + "_" + r
+		if _, ok := seen[row]; ok && sameChartRow(actions, row, KeyKindPair+"_10") {
+			delete(seen, row)
+		}
+	}
+
+	rows := make([]string, 0, len(seen))
+	for row := range seen {
+		rows = append(rows, row)
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		a, b := seen[rows[i]], seen[rows[j]]
+		if a.Namespace != b.Namespace {
+			return namespaceOrder(a.Namespace) < namespaceOrder(b.Namespace)
+		}
+		if a.Kind != b.Kind {
+			return chartKindOrder(a.Kind) < chartKindOrder(b.Kind)
+		}
+		if a.Kind == KeyKindPair {
+			return rankIndex(a.Player) < rankIndex(b.Player)
+		}
+		if a.Total() != b.Total() {
+			return a.Total() < b.Total()
+		}
+		return rows[i] < rows[j]
+	})
+	return rows
+}
+
+// namespaceOrder, normal anahtarları ad alanlı anahtarlardan önce, ad alanlarını KeyNamespaces sırasıyla dizer
+func namespaceOrder(ns string) int {
+	for i, n := range KeyNamespaces {
+		if n == ns {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// namespaceTitles, ad alanlı bölümlerin başlıklarıdır
+var namespaceTitles = map[string]string{
+	KeyNamespaceAfterSplit:          "after split",
+	KeyNamespaceAfterSplitNoDAS:     "after split, no DAS",
+	KeyNamespaceAfterSplitNoResplit: "after split, no resplit",
+}
+
+// chartSection, tabloda birlikte gösterilen satırların ön ekidir ("hard_", "after_split_pair_")
+type chartSection struct {
+	prefix, title string
+}
+
+// chartSections, satırlar için gösterilecek bölümleri döndürür. Normal hard/soft/pair bölümleri
+// her zaman, ad alanlı bölümler yalnızca satırı varsa yer alır.
+func chartSections(rows []string) []chartSection {
+	kinds := []chartSection{{KeyKindHard, "Hard"}, {KeyKindSoft, "Soft"}, {KeyKindPair, "Pairs"}}
+	sections := []chartSection{}
+	for _, ns := range append([]string{""}, KeyNamespaces...) {
+		for _, kind := range kinds {
+			sec := chartSection{prefix: kind.prefix + "_", title: kind.title}
+			if ns != "" {
+				sec = chartSection{prefix: ns + "_" + sec.prefix, title: fmt.Sprintf("%s (%s)", kind.title, namespaceTitles[ns])}
+				if !hasRowWithPrefix(rows, sec.prefix) {
+					continue
+				}
+			}
+			sections = append(sections, sec)
+		}
+	}
+	return sections
+}
+
+func hasRowWithPrefix(rows []string, prefix string) bool {
+	for _, row := range rows {
+		if strings.HasPrefix(row, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func sameChartRow(actions map[string][]string, a, b string) bool {
+	for _, d := range DealerKeys {
+		if !equalActions(actions[a+"_vs_"+d], actions[b+"_vs_"+d]) {
+			return false
+		}
+	}
+	return true
+}
+
+func chartKindOrder(kind string) int {
+	switch kind {
+	case KeyKindHard:
+		return 0
+	case KeyKindSoft:
+		return 1
+	}
+	return 2
+}
+
+func rankIndex(rank string) int {
+	for i, r := range Ranks {
+		if r == rank {
+			return i
+		}
+	}
+	return len(Ranks)
+}
+
+// ExportChart, aksiyonları tablo olarak yazar
+func ExportChart(w io.Writer, actions map[string][]string, format string) error {
+	header := append([]string{"hand"}, DealerKeys...)
+	table := [][]string{header}
+	for _, row := range ChartRows(actions) {
+		record := []string{row}
+		for _, d := range DealerKeys {
+			record = append(record, ChartCell(actions[row+"_vs_"+d]))
+		}
+		table = append(table, record)
+	}
+
+	if format == ChartFormatCSV {
+		for _, record := range table[1:] {
+			for i := range record {
+				if record[i] == "-" {
+					record[i] = ""
+				}
+			}
+		}
+		cw := csv.NewWriter(w)
+		if err := cw.WriteAll(table); err != nil {
+			return err
+		}
+		return cw.Error()
+	}
+	return writeAligned(w, table)
+}
+
+// writeAligned, tabloyu sütunları hizalanmış olarak yazar
+func writeAligned(w io.Writer, table [][]string) error {
+	widths := []int{}
+	for _, record := range table {
+		for i, cell := range record {
+			if i >= len(widths) {
+				widths = append(widths, 0)
+			}
+			widths[i] = max(widths[i], utf8.RuneCountInString(cell))
+		}
+	}
+	for _, record := range table {
+		parts := make([]string, len(record))
+		for i, cell := range record {
+			parts[i] = cell + strings.Repeat(" ", widths[i]-utf8.RuneCountInString(cell))
+		}
+		if _, err := fmt.Fprintln(w, strings.TrimRight(strings.Join(parts, "  "), " ")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ImportChart, tabloyu strateji anahtarlarına çevirir. İlk satır dealer kartlarını içeren başlıktır.
+// pair_10 satırı, tabloda ayrıca yazılmadıkça J/Q/K çiftlerine de uygulanır.
+func ImportChart(r io.Reader, format string) (map[string][]string, error) {
+	var table [][]string
+	if format == ChartFormatCSV {
+		cr := csv.NewReader(r)
+		cr.FieldsPerRecord = -1
+		cr.TrimLeadingSpace = true
+		records, err := cr.ReadAll()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read chart: %w", err)
+		}
+		table = records
+	} else {
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			if fields := strings.Fields(scanner.Text()); len(fields) > 0 {
+				table = append(table, fields)
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read chart: %w", err)
+		}
+	}
+	if len(table) == 0 {
+		return nil, fmt.Errorf("chart is empty")
+	}
+
+	dealers := []string{}
+	for _, cell := range table[0][1:] {
+		d := strings.ToUpper(strings.TrimSpace(cell))
+		if !isRank(d) {
+			return nil, fmt.Errorf("chart header: unknown dealer card %q", cell)
+		}
+		dealers = append(dealers, getDealerRankKey(Card{Rank: d}))
+	}
+
+	actions := map[string][]string{}
+	rows := map[string]bool{}
+	for n, record := range table[1:] {
+		line := n + 2
+		row, err := parseChartRow(record[0])
+		if err != nil {
+			return nil, fmt.Errorf("chart line %d: %w", line, err)
+		}
+		if rows[row] {
+			return nil, fmt.Errorf("chart line %d: duplicate row %q", line, row)
+		}
+		rows[row] = true
+		if len(record)-1 > len(dealers) {
+			return nil, fmt.Errorf("chart line %d: %d cells for %d dealer columns", line, len(record)-1, len(dealers))
+		}
+		for i, cell := range record[1:] {
+			list, err := ParseChartCell(cell)
+			if err != nil {
+				return nil, fmt.Errorf("chart line %d, dealer %s: %w", line, dealers[i], err)
+			}
+			if list != nil {
+				actions[row+"_vs_"+dealers[i]] = list
+			}
+		}
+	}
+
+	for _, r := range tenPairRanks {
+		row := KeyKindPair + "_" + r
+		if rows[row] || !rows[KeyKindPair+"_10"] {
+			continue
+		}
+		for _, d := range dealers {
+			if list, ok := actions[KeyKindPair+"_10_vs_"+d]; ok {
+				actions[row+"_vs_"+d] = append([]string{}, list...)
+			}
+		}
+	}
+	return actions, nil
+}
+
+// parseChartRow, satır etiketini anahtarın oyuncu kısmına çevirir. "hard_16" gibi anahtar
+// biçiminin yanında kısa "H16", "S18", "P8", "PA" biçimleri de kabul edilir.
+func parseChartRow(label string) (string, error) {
+	label = strings.TrimSpace(label)
+	row := strings.ToLower(label)
+	if len(label) >= 2 && !strings.Contains(label, "_") {
+		rest := strings.ToUpper(label[1:])
+		switch strings.ToUpper(label[:1]) {
+		case "H":
+			row = KeyKindHard + "_" + rest
+		case "S":
+			row = KeyKindSoft + "_" + rest
+		case "P":
+			row = KeyKindPair + "_" + rest
+		}
+	} else if prefix, player, ok := strings.Cut(row, KeyKindPair+"_"); ok {
+		row = prefix + KeyKindPair + "_" + strings.ToUpper(player) // ad alanlı çiftler de dahil (after_split_pair_A)
+	}
+	if _, err := ParseStrategyKey(row + "_vs_2"); err != nil {
+		return "", fmt.Errorf("unknown chart row %q", label)
+	}
+	return row, nil
+}
+
+// RenderChart, stratejiyi terminal için hard/soft/pair tabloları, sapmalar ve bahis rampası
+// olarak yazar.
+func RenderChart(w io.Writer, name string, data CountingStrategyFile) error {
+	fmt.Fprintf(w, "Strategy: %s (fallback: %s)\n", name, data.Fallback)
+	rows := ChartRows(data.Actions)
+	for _, sec := range chartSections(rows) {
+		table := [][]string{append([]string{sec.title}, DealerKeys...)}
+		for _, row := range rows {
+			if !strings.HasPrefix(row, sec.prefix) {
+				continue
+			}
+			record := []string{chartRowLabel(row)}
+			for _, d := range DealerKeys {
+				cell := ChartCell(data.Actions[row+"_vs_"+d])
+				if _, ok := data.Deviations[row+"_vs_"+d]; ok {
+					cell += "*" // bu hücrede sapma var
+				}
+				record = append(record, cell)
+			}
+			table = append(table, record)
+		}
+		fmt.Fprintln(w)
+		if err := writeAligned(w, table); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintln(w)
+	legend := []string{}
+	for _, c := range ChartCodes {
+		if c.Code != "Dh" {
+			legend = append(legend, c.Code+"="+strings.Join(c.Actions, "/"))
+		}
+	}
+	fmt.Fprintf(w, "%s  -=fallback  *=deviation\n", strings.Join(legend, " "))
+
+	if len(data.Deviations) > 0 {
+		fmt.Fprintln(w, "\nDeviations:")
+		for _, key := range sortedKeys(data.Deviations) {
+			fmt.Fprintf(w, "  %-16s %s\n", key, formatDeviation(data.Deviations[key]))
+		}
+	}
+	if len(data.BetRamp) > 0 {
+		fmt.Fprintln(w, "\nBet ramp:")
+		for _, tier := range data.BetRamp {
+			fmt.Fprintf(w, "  TC >= %-4d x%s\n", tier.MinCount, formatUnit(tier.BetUnit))
+		}
+	}
+	return nil
+}
+
+// chartRowLabel, satırın terminaldeki kısa etiketidir ("16", "A,7", "8,8", "16 (3 cards)").
+// Ad alanı bölüm başlığında yazıldığı için etikete eklenmez.
+func chartRowLabel(row string) string {
+	k, err := ParseStrategyKey(row + "_vs_2")
+	if err != nil {
+		return row
+	}
+	switch {
+	case k.Cards > 0:
+		return fmt.Sprintf("%s (%d cards)", k.Player, k.Cards)
+	case k.Kind == KeyKindPair:
+		return k.Player + "," + k.Player
+	case k.Kind == KeyKindSoft && k.Total() >= 13 && k.Total() <= 21:
+		return fmt.Sprintf("A,%d", k.Total()-11)
+	}
+	return k.Player
+}
@@ -0,0 +1,199 @@
+package engine
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseChartCell(t *testing.T) {
+	tests := []struct {
+		cell    string
+		want    []string
+		wantErr bool
+	}{
+		{cell: "", want: nil},
+		{cell: " - ", want: nil},
+		{cell: "H", want: []string{"hit"}},
+		{cell: "ds", want: []string{"double", "stand"}},
+		{cell: "Dh", want: []string{"double", "hit"}},
+		{cell: "Rp", want: []string{"surrender", "split"}},
+		{cell: "split/Double/hit", want: []string{"split", "double", "hit"}},
+		{cell: "X", wantErr: true},
+		{cell: "hit/dobule", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseChartCell(tt.cell)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseChartCell(%q) error %v, wantErr %v", tt.cell, err, tt.wantErr)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseChartCell(%q) = %v, want %v", tt.cell, got, tt.want)
+		}
+	}
+}
+
+func TestChartCell(t *testing.T) {
+	tests := []struct {
+		actions []string
+		want    string
+	}{
+		{nil, "-"},
+		{[]string{"double", "hit"}, "D"},
+		{[]string{"surrender", "stand"}, "Rs"},
+		{[]string{"split", "double", "hit"}, "Pd"},
+		{[]string{"double", "surrender", "hit"}, "double/surrender/hit"},
+	}
+	for _, tt := range tests {
+		if got := ChartCell(tt.actions); got != tt.want {
+			t.Errorf("ChartCell(%v) = %q, want %q", tt.actions, got, tt.want)
+		}
+	}
+}
+
+func TestParseChartRow(t *testing.T) {
+	tests := []struct {
+		label   string
+		want    string
+		wantErr bool
+	}{
+		{label: "hard_16", want: "hard_16"},
+		{label: "H16", want: "hard_16"},
+		{label: "s18", want: "soft_18"},
+		{label: "PA", want: "pair_A"},
+		{label: "pair_a", want: "pair_A"},
+		{label: "hard_16_3cards", want: "hard_16_3cards"},
+		{label: "after_split_hard_11", want: "after_split_hard_11"},
+		{label: "after_split_pair_A", want: "after_split_pair_A"},
+		{label: "after_split_no_resplit_pair_k", want: "after_split_no_resplit_pair_K"},
+		{label: "X16", wantErr: true},
+		{label: "pair_1", wantErr: true},
+		{label: "after_split_16", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseChartRow(tt.label)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseChartRow(%q) error %v, wantErr %v", tt.label, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseChartRow(%q) = %q, want %q", tt.label, got, tt.want)
+		}
+	}
+}
+
+func TestChartRows(t *testing.T) {
+	rows := ChartRows(map[string][]string{
+		"hard_16_3cards_vs_10":           {"stand"},
+		"after_split_no_das_hard_9_vs_3": {"hit"},
+		"after_split_pair_A_vs_6":        {"split"},
+		"pair_K_vs_6":                    {"stand"},
+	})
+	index := map[string]int{}
+	for i, row := range rows {
+		index[row] = i
+	}
+	// pair_10'dan farklı olmayan J/Q satırları gösterilmez, farklı olan K satırı gösterilir
+	for _, row := range []string{"pair_J", "pair_Q"} {
+		if _, ok := index[row]; ok {
+			t.Errorf("row %s should be folded into pair_10", row)
+		}
+	}
+	order := []string{"hard_5", "hard_16", "hard_16_3cards", "soft_13", "pair_2", "pair_10", "pair_K", "pair_A", "after_split_pair_A", "after_split_no_das_hard_9"}
+	for i := 1; i < len(order); i++ {
+		a, okA := index[order[i-1]]
+		b, okB := index[order[i]]
+		if !okA || !okB || a >= b {
+			t.Errorf("row %s (%d, %v) should come before %s (%d, %v)", order[i-1], a, okA, order[i], b, okB)
+		}
+	}
+}
+
+func TestChartRoundTrip(t *testing.T) {
+	actions := map[string][]string{
+		"hard_16_vs_10":                   {"surrender", "hit"},
+		"hard_11_vs_6":                    {"double", "hit"},
+		"soft_18_vs_3":                    {"double", "stand"},
+		"pair_8_vs_A":                     {"surrender", "split"},
+		"pair_10_vs_6":                    {"stand"},
+		"pair_J_vs_6":                     {"stand"},
+		"pair_Q_vs_6":                     {"stand"},
+		"pair_K_vs_6":                     {"stand"},
+		"hard_16_3cards_vs_10":            {"stand"},
+		"soft_17_vs_4":                    {"double", "surrender", "hit"},
+		"after_split_pair_A_vs_6":         {"split", "hit"},
+		"after_split_no_das_hard_11_vs_6": {"hit"},
+	}
+	for _, format := range []string{ChartFormatCSV, ChartFormatGrid} {
+		var buf bytes.Buffer
+		if err := ExportChart(&buf, actions, format); err != nil {
+			t.Fatalf("%s: export: %v", format, err)
+		}
+		got, err := ImportChart(&buf, format)
+		if err != nil {
+			t.Fatalf("%s: import: %v", format, err)
+		}
+		if !reflect.DeepEqual(got, actions) {
+			t.Errorf("%s: round trip = %v, want %v", format, got, actions)
+		}
+	}
+}
+
+func TestImportChartErrors(t *testing.T) {
+	tests := []struct {
+		chart string
+		want  string
+	}{
+		{"", "chart is empty"},
+		{"hand 2 X\nH16 H H", `unknown dealer card "X"`},
+		{"hand 2 3\nH16 H H\nhard_16 S S", `chart line 3: duplicate row "hard_16"`},
+		{"hand 2 3\nH16 H H H", "chart line 2: 3 cells for 2 dealer columns"},
+		{"hand 2 3\nH16 H Q", "chart line 2, dealer 3: unknown chart code"},
+		{"hand 2 3\nZ16 H H", `chart line 2: unknown chart row "Z16"`},
+	}
+	for _, tt := range tests {
+		_, err := ImportChart(strings.NewReader(tt.chart), ChartFormatGrid)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("ImportChart(%q) error %v, want %q", tt.chart, err, tt.want)
+		}
+	}
+}
+
+func TestChartRowLabel(t *testing.T) {
+	tests := []struct {
+		row  string
+		want string
+	}{
+		{"hard_16", "16"},
+		{"soft_18", "A,7"},
+		{"pair_A", "A,A"},
+		{"hard_16_3cards", "16 (3 cards)"},
+		{"after_split_pair_8", "8,8"},
+		{"after_split_no_das_soft_13", "A,2"},
+	}
+	for _, tt := range tests {
+		if got := chartRowLabel(tt.row); got != tt.want {
+			t.Errorf("chartRowLabel(%q) = %q, want %q", tt.row, got, tt.want)
+		}
+	}
+}
+
+// Sapma listesi diff ile aynı biçimi kullanır; side count düzeltmesi de gösterilir
+func TestRenderChartDeviations(t *testing.T) {
+	data := CountingStrategyFile{Fallback: "stand", Deviations: map[string]DeviationRule{
+		"hard_16_vs_10": {AtCount: 0, Action: "stand"},
+		"hard_12_vs_3":  {AtCount: 2, Action: "stand", SideCountAdjusted: true},
+	}}
+	var buf bytes.Buffer
+	if err := RenderChart(&buf, "test", data); err != nil {
+		t.Fatal(err)
+	}
+	for key, dev := range data.Deviations {
+		if line := fmt.Sprintf("%-16s %s", key, formatDeviation(dev)); !strings.Contains(buf.String(), line) {
+			t.Errorf("chart is missing %q:\n%s", line, buf.String())
+		}
+	}
+}
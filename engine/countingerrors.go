@@ -0,0 +1,73 @@
+package engine
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// CountingErrorModel, strateji dosyasındaki "counting_errors" bölümüdür. Gerçek bir sayıcının
+// yaptığı hataları modelleyerek stratejinin avantajının uygulamadaki hatalara ne kadar dayanıklı
+// olduğunu ölçmeye yarar.
+type CountingErrorModel struct {
+	MisTagRate     float64 `json:"mis_tag_rate"`    // bir kartın yanlış değerle sayılma olasılığı
+	ForgetRate     float64 `json:"forget_rate"`     // her kartta count'un unutulup sıfırdan başlanma olasılığı
+	DeckEstimation string  `json:"deck_estimation"` // boş değilse true_count.deck_estimation yerine geçer
+	TCRounding     string  `json:"tc_rounding"`     // boş değilse true_count.rounding yerine geçer
+}
+
+// humanCounter, shoe'yu izleyerek hatalı olabilecek kendi running count'unu tutar
+type humanCounter struct {
+	model        *CountingErrorModel
+	RunningCount int
+}
+
+func (c *humanCounter) OnCardDealt(card Card) {
+	tag := getHiLoValue(card)
+	if c.model.MisTagRate > 0 && rand.Float64() < c.model.MisTagRate {
+		tag = misTag(tag)
+	}
+	c.RunningCount += tag
+	if c.model.ForgetRate > 0 && rand.Float64() < c.model.ForgetRate {
+		c.RunningCount = 0
+	}
+}
+
+func (c *humanCounter) OnShuffle() {
+	c.RunningCount = 0
+}
+
+// OnCardsReturned: makineye dönen kartların etkisi sayımdan çıkarılır. Sayıcı dönen kartları
+// kendi saydığı değerlerle bilemeyeceğinden doğru Hi-Lo değerleri düşülür.
+func (c *humanCounter) OnCardsReturned(cards []Card) {
+	for _, card := range cards {
+		c.RunningCount -= getHiLoValue(card)
+	}
+}
+
+// misTag, gerçek değer dışındaki Hi-Lo değerlerinden birini rastgele seçer
+func misTag(tag int) int {
+	others := []int{}
+	for _, v := range []int{-1, 0, 1} {
+		if v != tag {
+			others = append(others, v)
+		}
+	}
+	return others[rand.Intn(len(others))]
+}
+
+func validateCountingErrors(m *CountingErrorModel) []ValidationIssue {
+	issues := []ValidationIssue{}
+	if m == nil {
+		return issues
+	}
+	add := func(format string, args ...interface{}) {
+		issues = append(issues, ValidationIssue{Severity: SeverityError, Section: "counting_errors", Message: fmt.Sprintf(format, args...)})
+	}
+	if m.MisTagRate < 0 || m.MisTagRate > 1 {
+		add("mis_tag_rate must be between 0 and 1, got %g", m.MisTagRate)
+	}
+	if m.ForgetRate < 0 || m.ForgetRate > 1 {
+		add("forget_rate must be between 0 and 1, got %g", m.ForgetRate)
+	}
+	return append(issues, validateTrueCountFields("counting_errors", m.DeckEstimation, m.TCRounding, "tc_rounding")...)
+}
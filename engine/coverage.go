@@ -0,0 +1,165 @@
+package engine
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// KeyCoverage, bir stratejinin tek bir anahtarı için simülasyon boyunca toplanan istatistiklerdir.
+type KeyCoverage struct {
+	Strategy     string         `json:"strategy"`
+	Key          string         `json:"key"`
+	Decisions    int            `json:"decisions"`
+	Fallbacks    int            `json:"fallbacks"`
+	Deviations   int            `json:"deviations"`
+	FinalActions map[string]int `json:"final_actions"`
+	Hands        int            `json:"hands"`     // bu anahtarda karar verilen el sayısı (split çocukları ayrı sayılır)
+	TotalBet     float64        `json:"total_bet"` // bu ellerin toplam ana bahsi (double dahil)
+	Net          float64        `json:"net"`       // bu ellerin net sonucu (payout - bet)
+}
+
+// EVPerHand, anahtara dokunan ellerin ortalama net sonucudur
+func (k *KeyCoverage) EVPerHand() float64 {
+	if k.Hands == 0 {
+		return 0
+	}
+	return k.Net / float64(k.Hands)
+}
+
+// CoverageReport, strateji hücrelerinin simülasyonda ne sıklıkla kullanıldığını toplar.
+// Büyük CSV log'undaki decision_trace sütununu sonradan ayrıştırma ihtiyacını ortadan kaldırır.
+type CoverageReport struct {
+	entries map[string]*KeyCoverage
+}
+
+func NewCoverageReport() *CoverageReport {
+	return &CoverageReport{entries: map[string]*KeyCoverage{}}
+}
+
+func (r *CoverageReport) entry(strategy, key string) *KeyCoverage {
+	id := strategy + "|" + key
+	kc, ok := r.entries[id]
+	if !ok {
+		kc = &KeyCoverage{Strategy: strategy, Key: key, FinalActions: map[string]int{}}
+		r.entries[id] = kc
+	}
+	return kc
+}
+
+// RecordBox, round sonunda (payout'lar hesaplandıktan sonra) box'taki ellerin karar izlerini işler.
+// Split çocuklarına kopyalanan üst el kararları sıklığa bir kez sayılır, ancak sonuçları her çocuğa eklenir.
+func (r *CoverageReport) RecordBox(box *Box) {
+	if box == nil || box.Player == nil {
+		return
+	}
+	strategy := box.Player.Strategy.String()
+	for _, hand := range box.Hands {
+		net := hand.Payout - hand.BetAmount
+		touched := map[string]bool{}
+		for i, d := range hand.DecisionTrace {
+			if d.Key == NoDecisionKey {
+				continue
+			}
+			kc := r.entry(strategy, d.Key)
+			if i >= hand.InheritedDecisions {
+				kc.Decisions++
+				if d.IsFallback {
+					kc.Fallbacks++
+				}
+				if d.IsDeviation {
+					kc.Deviations++
+				}
+				kc.FinalActions[d.FinalAction]++
+			}
+			if !touched[d.Key] {
+				touched[d.Key] = true
+				kc.Hands++
+				kc.TotalBet += hand.BetAmount
+				kc.Net += net
+			}
+		}
+	}
+}
+
+// Rows, raporu strateji ve anahtara göre sıralı döndürür
+func (r *CoverageReport) Rows() []*KeyCoverage {
+	rows := make([]*KeyCoverage, 0, len(r.entries))
+	for _, kc := range r.entries {
+		rows = append(rows, kc)
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].Strategy != rows[j].Strategy {
+			return rows[i].Strategy < rows[j].Strategy
+		}
+		return rows[i].Key < rows[j].Key
+	})
+	return rows
+}
+
+// WriteFile, raporu dosya uzantısına göre JSON ya da CSV olarak yazar
+func (r *CoverageReport) WriteFile(path string) error {
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		return r.writeJSON(path)
+	}
+	return r.writeCSV(path)
+}
+
+func (r *CoverageReport) writeJSON(path string) error {
+	type row struct {
+		*KeyCoverage
+		EVPerHand float64 `json:"ev_per_hand"`
+	}
+	rows := []row{}
+	for _, kc := range r.Rows() {
+		rows = append(rows, row{KeyCoverage: kc, EVPerHand: kc.EVPerHand()})
+	}
+	out, err := json.MarshalIndent(rows, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, out, 0644)
+}
+
+func (r *CoverageReport) writeCSV(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := csv.NewWriter(f)
+	header := []string{"strategy", "key", "decisions", "fallbacks", "deviations"}
+	for _, a := range KnownActions {
+		header = append(header, "final_"+a)
+	}
+	header = append(header, "hands", "total_bet", "net", "ev_per_hand")
+	w.Write(header)
+
+	for _, kc := range r.Rows() {
+		record := []string{
+			kc.Strategy,
+			kc.Key,
+			strconv.Itoa(kc.Decisions),
+			strconv.Itoa(kc.Fallbacks),
+			strconv.Itoa(kc.Deviations),
+		}
+		for _, a := range KnownActions {
+			record = append(record, strconv.Itoa(kc.FinalActions[a]))
+		}
+		record = append(record,
+			strconv.Itoa(kc.Hands),
+			fmt.Sprintf("%.2f", kc.TotalBet),
+			fmt.Sprintf("%.2f", kc.Net),
+			fmt.Sprintf("%.4f", kc.EVPerHand()),
+		)
+		w.Write(record)
+	}
+	w.Flush()
+	return w.Error()
+}
@@ -21,7 +21,7 @@ func (d *Dealer) Play(deck *Deck, hitOnSoft17 bool) {
 			break
 		}
 		if value == 17 {
-			if hitOnSoft17 && hasAce(d.Hand.Cards) {
+			if hitOnSoft17 && d.Hand.IsSoft() {
 				card, _ := deck.DealCard()
 				d.Hand.AddCard(card)
 				continue
@@ -54,12 +54,3 @@ func (d *Dealer) Evaluate(playerHand *Hand) string {
 	}
 	return "push"
 }
-
-func hasAce(cards []Card) bool {
-	for _, c := range cards {
-		if c.Rank == "A" {
-			return true
-		}
-	}
-	return false
-}
@@ -0,0 +1,120 @@
+package engine
+
+// CountState, karar anında masadaki gerçek sayım durumudur (Hi-Lo). Kendi sayıcısı olan
+// stratejiler (ör. counting_errors) bunun yerine kendi algıladıkları değeri kullanabilir.
+type CountState struct {
+	RunningCount   int
+	TrueCount      float64
+	DecksRemaining float64
+	CardsDealt     int // bu shoe'da dağıtılan (yakılanlar dahil) kart sayısı
+}
+
+// DecisionContext, Strategy.GetAction'a verilen karar anı bilgisidir. Engine kurallara göre
+// hangi aksiyonların uygulanabileceğini hesaplar; böylece stratejiler fallback listelerine
+// güvenmek yerine yalnızca yasal aksiyonları önerebilir.
+type DecisionContext struct {
+	Hand     *Hand
+	DealerUp Card
+	Visible  *VisibleCards // karar anında görülebilen diğer kartlar
+
+	CanDouble    bool
+	CanSplit     bool
+	CanSurrender bool
+
+	SplitCount       int     // box'ta bu round yapılan split sayısı
+	MaxSplitsReached bool    // box MaxSplits sınırına ulaştı, çiftler tekrar bölünemez
+	AllowDAS         bool    // kurallar split sonrası double'a izin veriyor
+	HandsInBox       int     // box'taki el sayısı (split'lerle artar)
+	Balance          float64 // oyuncunun o anki bakiyesi
+	Round            int
+	Shoe             int
+	Count            CountState
+}
+
+// LegalActions, bu karar için uygulanabilecek aksiyonları executeBoxActions'ın denediği sırayla döndürür
+func (c *DecisionContext) LegalActions() []string {
+	actions := []string{}
+	if c.CanSurrender {
+		actions = append(actions, "surrender")
+	}
+	if c.CanSplit {
+		actions = append(actions, "split")
+	}
+	if c.CanDouble {
+		actions = append(actions, "double")
+	}
+	return append(actions, "hit", "stand")
+}
+
+// IsLegal, aksiyonun bu kararda uygulanıp uygulanamayacağını döndürür
+func (c *DecisionContext) IsLegal(action string) bool {
+	switch action {
+	case "surrender":
+		return c.CanSurrender
+	case "split":
+		return c.CanSplit
+	case "double":
+		return c.CanDouble
+	case "hit", "stand":
+		return true
+	}
+	return false
+}
+
+// FirstLegal, listedeki ilk yasal aksiyonu döndürür; yoksa "stand"
+func (c *DecisionContext) FirstLegal(actions []string) string {
+	for _, a := range actions {
+		if c.IsLegal(a) {
+			return a
+		}
+	}
+	return "stand"
+}
+
+// canSurrender, kurallara göre elin teslim edilip edilemeyeceğini döndürür
+func (e *Engine) canSurrender(hand *Hand) bool {
+	if e.Dealer.Hand.Cards[0].Rank == "A" && !e.SurrenderAgainstAce {
+		return false
+	}
+	return e.AllowSurrender && len(hand.Cards) == 2 && !hand.IsSplitChild
+}
+
+// canSplit, kurallara göre elin bölünüp bölünemeyeceğini döndürür (bakiye kontrolü hariç)
+func (e *Engine) canSplit(box *Box, hand *Hand) bool {
+	return hand.CanSplit() && len(box.Hands) < e.MaxSplits+1
+}
+
+// canDouble, kurallara göre ele double yapılıp yapılamayacağını döndürür (bakiye kontrolü hariç).
+// Split sonrası double DAS açıksa yapılabilir; DoubleFirstTwoOnly açıksa yalnızca ilk iki kartla.
+func (e *Engine) canDouble(hand *Hand) bool {
+	if e.DoubleFirstTwoOnly && len(hand.Cards) != 2 {
+		return false
+	}
+	return !(hand.IsSplitChild && !e.AllowDAS)
+}
+
+// decisionContext, box'taki el için karar anı bilgisini oluşturur
+func (e *Engine) decisionContext(box *Box, hand *Hand) *DecisionContext {
+	p := box.Player
+	return &DecisionContext{
+		Hand:             hand,
+		DealerUp:         e.Dealer.Hand.Cards[0],
+		Visible:          e.visibleCards(p, hand),
+		CanDouble:        e.canDouble(hand) && p.CanBet(hand.BetAmount),
+		CanSplit:         e.canSplit(box, hand) && p.CanBet(hand.BetAmount),
+		CanSurrender:     e.canSurrender(hand),
+		SplitCount:       box.SplitCount,
+		MaxSplitsReached: len(box.Hands) >= e.MaxSplits+1,
+		AllowDAS:         e.AllowDAS,
+		HandsInBox:       len(box.Hands),
+		Balance:          p.Balance,
+		Round:            e.CurrentRound,
+		Shoe:             e.CurrentShoeNumber,
+		Count: CountState{
+			RunningCount:   e.Deck.RunningCount,
+			TrueCount:      e.Deck.TrueCount(),
+			DecksRemaining: float64(len(e.Deck.Cards)) / 52.0,
+			CardsDealt:     e.Deck.DrawnThisShoe,
+		},
+	}
+}
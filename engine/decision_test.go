@@ -0,0 +1,25 @@
+package engine
+
+import "testing"
+
+func TestCanDoubleRules(t *testing.T) {
+	split := func(h *Hand) *Hand { h.IsSplitChild = true; return h }
+	tests := []struct {
+		hand          *Hand
+		das, twoCards bool
+		want          bool
+	}{
+		{testHand("5", "6"), false, false, true},
+		{testHand("2", "3", "6"), false, false, true},
+		{testHand("2", "3", "6"), false, true, false},
+		{split(testHand("5", "6")), false, false, false},
+		{split(testHand("5", "6")), true, true, true},
+	}
+	for _, tt := range tests {
+		e := &Engine{AllowDAS: tt.das, DoubleFirstTwoOnly: tt.twoCards}
+		if got := e.canDouble(tt.hand); got != tt.want {
+			t.Errorf("canDouble(%d cards, split %v, das %v, two cards only %v) = %v, want %v",
+				len(tt.hand.Cards), tt.hand.IsSplitChild, tt.das, tt.twoCards, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,253 @@
+package engine
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ActionChange, iki stratejide farklı oynanan bir anahtardır. Eksik anahtarlarda liste boştur
+// (fallback kullanılır).
+type ActionChange struct {
+	Key  string   `json:"key"`
+	From []string `json:"from"`
+	To   []string `json:"to"`
+}
+
+// DeviationChange, eklenen (From nil), kaldırılan (To nil) ya da değişen bir sapmadır
+type DeviationChange struct {
+	Key  string         `json:"key"`
+	From *DeviationRule `json:"from,omitempty"`
+	To   *DeviationRule `json:"to,omitempty"`
+}
+
+// BetRampChange, min_count'a göre eşlenen bahis rampası kademesi farkıdır
+type BetRampChange struct {
+	MinCount int      `json:"min_count"`
+	From     *float64 `json:"from,omitempty"`
+	To       *float64 `json:"to,omitempty"`
+}
+
+// StrategyDiff, iki strateji dosyası arasındaki farklardır
+type StrategyDiff struct {
+	Fallback   []string          `json:"fallback,omitempty"` // [a, b]; fallback aynıysa nil
+	Actions    []ActionChange    `json:"actions"`
+	Deviations []DeviationChange `json:"deviations"`
+	BetRamp    []BetRampChange   `json:"bet_ramp"`
+}
+
+// Empty, stratejilerin aynı oynanıp aynı bahis yaptığını döndürür
+func (d StrategyDiff) Empty() bool {
+	return d.Fallback == nil && len(d.Actions) == 0 && len(d.Deviations) == 0 && len(d.BetRamp) == 0
+}
+
+// effectiveActions, aksiyon listesini engine'in davranışına göre sadeleştirir: eksik anahtar
+// fallback'tir, bilinmeyen ve tekrar eden aksiyonlar atlanır, her zaman uygulanabilen hit/stand'den
+// sonrası hiç denenmez. Böylece ["double","hit","stand"] ile ["double","hit"] eşit sayılır.
+func effectiveActions(actions []string, fallback string) []string {
+	if len(actions) == 0 {
+		if fallback == "" {
+			fallback = "stand"
+		}
+		actions = []string{fallback}
+	}
+	out := []string{}
+	seen := map[string]bool{}
+	for _, a := range actions {
+		if !isKnownAction(a) || seen[a] {
+			continue
+		}
+		seen[a] = true
+		out = append(out, a)
+		if a == "hit" || a == "stand" {
+			break
+		}
+	}
+	return out
+}
+
+// resolvedActions, anahtarın aksiyonlarını döndürür; eksik ad alanlı ya da kart sayısına özel
+// anahtarlarda engine gibi FallbackKeys sırasıyla daha genel anahtarlara bakar. Hiçbiri yoksa
+// nil (fallback) döner.
+func resolvedActions(actions map[string][]string, key string) []string {
+	k, err := ParseStrategyKey(key)
+	if err != nil {
+		return actions[key]
+	}
+	for _, f := range k.FallbackKeys() {
+		if list, ok := actions[f.String()]; ok {
+			return list
+		}
+	}
+	return nil
+}
+
+// DiffStrategies, a'dan b'ye aksiyon, sapma ve bahis rampası farklarını bulur
+func DiffStrategies(a, b CountingStrategyFile) StrategyDiff {
+	diff := StrategyDiff{Actions: []ActionChange{}, Deviations: []DeviationChange{}, BetRamp: []BetRampChange{}}
+	if a.Fallback != b.Fallback {
+		diff.Fallback = []string{a.Fallback, b.Fallback}
+	}
+
+	keys := map[string]bool{}
+	for _, k := range ReachableStrategyKeys() {
+		keys[k] = true
+	}
+	for _, m := range []map[string][]string{a.Actions, b.Actions} {
+		for k := range m {
+			if _, err := ParseStrategyKey(k); err == nil {
+				keys[k] = true
+			}
+		}
+	}
+	for _, k := range sortedKeys(keys) {
+		if !equalActions(effectiveActions(resolvedActions(a.Actions, k), a.Fallback), effectiveActions(resolvedActions(b.Actions, k), b.Fallback)) {
+			diff.Actions = append(diff.Actions, ActionChange{Key: k, From: a.Actions[k], To: b.Actions[k]})
+		}
+	}
+
+	devKeys := map[string]bool{}
+	for _, m := range []map[string]DeviationRule{a.Deviations, b.Deviations} {
+		for k := range m {
+			devKeys[k] = true
+		}
+	}
+	for _, k := range sortedKeys(devKeys) {
+		from, inA := a.Deviations[k]
+		to, inB := b.Deviations[k]
+		if inA && inB && from == to {
+			continue
+		}
+		change := DeviationChange{Key: k}
+		if inA {
+			change.From = &from
+		}
+		if inB {
+			change.To = &to
+		}
+		diff.Deviations = append(diff.Deviations, change)
+	}
+
+	tiers := map[int][2]*float64{}
+	for side, ramp := range [][]BetRampTier{a.BetRamp, b.BetRamp} {
+		for _, tier := range ramp {
+			unit := tier.BetUnit
+			t := tiers[tier.MinCount]
+			t[side] = &unit
+			tiers[tier.MinCount] = t
+		}
+	}
+	counts := []int{}
+	for c := range tiers {
+		counts = append(counts, c)
+	}
+	sort.Ints(counts)
+	for _, c := range counts {
+		t := tiers[c]
+		if t[0] != nil && t[1] != nil && *t[0] == *t[1] {
+			continue
+		}
+		diff.BetRamp = append(diff.BetRamp, BetRampChange{MinCount: c, From: t[0], To: t[1]})
+	}
+	return diff
+}
+
+// RenderStrategyDiff, farkları terminal için yazar: aksiyon farkları tablo halinde (yalnızca
+// farklı hücresi olan satırlar, "H→S" biçiminde), ardından sapma ve bahis rampası farkları.
+func RenderStrategyDiff(w io.Writer, nameA, nameB string, diff StrategyDiff) error {
+	fmt.Fprintf(w, "--- %s\n+++ %s\n", nameA, nameB)
+	if diff.Empty() {
+		fmt.Fprintln(w, "\nNo differences.")
+		return nil
+	}
+	if diff.Fallback != nil {
+		fmt.Fprintf(w, "\nFallback: %s → %s\n", diff.Fallback[0], diff.Fallback[1])
+	}
+
+	if len(diff.Actions) > 0 {
+		// Hücre değerleri aynı olan J/Q/K çiftleri ChartRows'ta 10 satırına katlanır
+		cells := map[string][]string{}
+		for _, c := range diff.Actions {
+			cells[c.Key] = []string{ChartCell(c.From) + "→" + ChartCell(c.To)}
+		}
+		rows := ChartRows(cells)
+		for _, sec := range chartSections(rows) {
+			table := [][]string{append([]string{sec.title}, DealerKeys...)}
+			for _, row := range rows {
+				if !strings.HasPrefix(row, sec.prefix) {
+					continue
+				}
+				record := []string{chartRowLabel(row)}
+				changed := false
+				for _, d := range DealerKeys {
+					cell := "."
+					if c, ok := cells[row+"_vs_"+d]; ok {
+						cell, changed = c[0], true
+					}
+					record = append(record, cell)
+				}
+				if changed {
+					table = append(table, record)
+				}
+			}
+			if len(table) == 1 {
+				continue
+			}
+			fmt.Fprintln(w)
+			if err := writeAligned(w, table); err != nil {
+				return err
+			}
+		}
+		fmt.Fprintf(w, "\n%d key(s) differ", len(diff.Actions))
+		for _, c := range diff.Actions {
+			if len(c.From) == 0 || len(c.To) == 0 {
+				fmt.Fprint(w, " (- = missing, the more general key or fallback is used)")
+				break
+			}
+		}
+		fmt.Fprintln(w)
+	}
+
+	if len(diff.Deviations) > 0 {
+		fmt.Fprintln(w, "\nDeviations:")
+		for _, c := range diff.Deviations {
+			switch {
+			case c.From == nil:
+				fmt.Fprintf(w, "  + %-16s %s\n", c.Key, formatDeviation(*c.To))
+			case c.To == nil:
+				fmt.Fprintf(w, "  - %-16s %s\n", c.Key, formatDeviation(*c.From))
+			default:
+				fmt.Fprintf(w, "  ~ %-16s %s → %s\n", c.Key, formatDeviation(*c.From), formatDeviation(*c.To))
+			}
+		}
+	}
+
+	if len(diff.BetRamp) > 0 {
+		fmt.Fprintln(w, "\nBet ramp:")
+		for _, c := range diff.BetRamp {
+			switch {
+			case c.From == nil:
+				fmt.Fprintf(w, "  + TC >= %-4d x%s\n", c.MinCount, formatUnit(*c.To))
+			case c.To == nil:
+				fmt.Fprintf(w, "  - TC >= %-4d x%s\n", c.MinCount, formatUnit(*c.From))
+			default:
+				fmt.Fprintf(w, "  ~ TC >= %-4d x%s → x%s\n", c.MinCount, formatUnit(*c.From), formatUnit(*c.To))
+			}
+		}
+	}
+	return nil
+}
+
+func formatDeviation(d DeviationRule) string {
+	s := fmt.Sprintf("%s at TC >= %d", d.Action, d.AtCount)
+	if d.SideCountAdjusted {
+		s += " (side count adjusted)"
+	}
+	return s
+}
+
+func formatUnit(u float64) string {
+	return strconv.FormatFloat(u, 'f', -1, 64)
+}
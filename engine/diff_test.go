@@ -0,0 +1,138 @@
+package engine
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEffectiveActions(t *testing.T) {
+	tests := []struct {
+		actions  []string
+		fallback string
+		want     []string
+	}{
+		{nil, "hit", []string{"hit"}},
+		{nil, "", []string{"stand"}},
+		{[]string{"double", "hit", "stand"}, "", []string{"double", "hit"}},
+		{[]string{"double", "double", "stand"}, "", []string{"double", "stand"}},
+		{[]string{"dobule", "surrender", "hit"}, "", []string{"surrender", "hit"}},
+		{[]string{"split", "double"}, "", []string{"split", "double"}},
+	}
+	for _, tt := range tests {
+		if got := effectiveActions(tt.actions, tt.fallback); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("effectiveActions(%v, %q) = %v, want %v", tt.actions, tt.fallback, got, tt.want)
+		}
+	}
+}
+
+func TestResolvedActions(t *testing.T) {
+	actions := map[string][]string{
+		"hard_16_vs_10":            {"surrender", "hit"},
+		"hard_11_vs_6":             {"double", "hit"},
+		"after_split_hard_11_vs_6": {"hit"},
+	}
+	tests := []struct {
+		key  string
+		want []string
+	}{
+		{"hard_16_vs_10", []string{"surrender", "hit"}},
+		{"hard_16_3cards_vs_10", []string{"surrender", "hit"}},
+		{"after_split_no_das_hard_11_vs_6", []string{"hit"}},
+		{"after_split_hard_16_vs_10", []string{"surrender", "hit"}},
+		{"hard_12_vs_2", nil},
+		{"bogus", nil},
+	}
+	for _, tt := range tests {
+		if got := resolvedActions(actions, tt.key); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("resolvedActions(%q) = %v, want %v", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestDiffStrategiesActions(t *testing.T) {
+	a := CountingStrategyFile{Fallback: "hit", Actions: map[string][]string{
+		"hard_16_vs_10": {"hit"},
+		"hard_12_vs_2":  {"hit", "stand"},
+		"hard_11_vs_6":  {"double", "hit"},
+	}}
+	b := CountingStrategyFile{Fallback: "hit", Actions: map[string][]string{
+		"hard_16_vs_10":            {"surrender", "hit"},
+		"hard_11_vs_6":             {"double", "hit"},
+		"after_split_hard_11_vs_6": {"double", "hit"},
+		"hard_13_3cards_vs_2":      {"hit"},
+		"hard_13_vs_2":             {"stand"},
+	}}
+	// hard_12_vs_2 b'de eksik ama fallback aynı sonucu verir; after_split_hard_11_vs_6 genel
+	// anahtarla, hard_13_3cards_vs_2 de a'daki fallback (hit) ile aynı oynanır
+	want := []ActionChange{
+		{Key: "hard_13_vs_2", From: nil, To: []string{"stand"}},
+		{Key: "hard_16_vs_10", From: []string{"hit"}, To: []string{"surrender", "hit"}},
+	}
+	got := DiffStrategies(a, b).Actions
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("actions diff = %+v, want %+v", got, want)
+	}
+}
+
+func TestDiffStrategiesDeviationsAndBetRamp(t *testing.T) {
+	a := CountingStrategyFile{
+		Deviations: map[string]DeviationRule{
+			"hard_16_vs_10": {AtCount: 0, Action: "stand"},
+			"hard_12_vs_3":  {AtCount: 2, Action: "stand"},
+			"hard_15_vs_10": {AtCount: 4, Action: "stand"},
+		},
+		BetRamp: []BetRampTier{{1, 2}, {2, 4}, {3, 6}},
+	}
+	b := CountingStrategyFile{
+		Deviations: map[string]DeviationRule{
+			"hard_16_vs_10": {AtCount: 0, Action: "stand"},
+			"hard_12_vs_3":  {AtCount: 3, Action: "stand"},
+			"hard_13_vs_2":  {AtCount: -1, Action: "stand"},
+		},
+		BetRamp: []BetRampTier{{1, 2}, {2, 5}, {4, 8}},
+	}
+	diff := DiffStrategies(a, b)
+
+	devs := map[string][2]bool{}
+	for _, c := range diff.Deviations {
+		devs[c.Key] = [2]bool{c.From != nil, c.To != nil}
+	}
+	wantDevs := map[string][2]bool{"hard_12_vs_3": {true, true}, "hard_13_vs_2": {false, true}, "hard_15_vs_10": {true, false}}
+	if !reflect.DeepEqual(devs, wantDevs) {
+		t.Errorf("deviation changes = %v, want %v", devs, wantDevs)
+	}
+
+	ramp := map[int][2]float64{}
+	for _, c := range diff.BetRamp {
+		var r [2]float64
+		if c.From != nil {
+			r[0] = *c.From
+		}
+		if c.To != nil {
+			r[1] = *c.To
+		}
+		ramp[c.MinCount] = r
+	}
+	wantRamp := map[int][2]float64{2: {4, 5}, 3: {6, 0}, 4: {0, 8}}
+	if !reflect.DeepEqual(ramp, wantRamp) {
+		t.Errorf("bet ramp changes = %v, want %v", ramp, wantRamp)
+	}
+}
+
+func TestDiffStrategiesEquivalent(t *testing.T) {
+	a := CountingStrategyFile{Fallback: "stand", Actions: map[string][]string{"hard_11_vs_6": {"double", "hit", "stand"}}}
+	b := CountingStrategyFile{Fallback: "stand", Actions: map[string][]string{"hard_11_vs_6": {"double", "hit"}, "hard_12_vs_2": {"stand"}}}
+	diff := DiffStrategies(a, b)
+	if !diff.Empty() {
+		t.Errorf("diff = %+v, want empty", diff)
+	}
+	var buf bytes.Buffer
+	if err := RenderStrategyDiff(&buf, "a", "b", diff); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "No differences.") {
+		t.Errorf("render = %q", buf.String())
+	}
+}
@@ -2,7 +2,7 @@ package engine
 
 import (
 	"fmt"
-	"os"
+	"math/rand"
 	"simjack/config"
 	"strings" 
 )
@@ -17,6 +17,7 @@ type Engine struct {
 	CurrentShoeNumber   int
 	HitOnSoft17         bool
 	AllowDAS            bool
+	DoubleFirstTwoOnly  bool
 	AllowSurrender      bool
 	SurrenderAgainstAce bool
 	DealerTakesHoleCard bool
@@ -29,39 +30,96 @@ type Engine struct {
 	MinSideBet float64
 	MaxSideBet float64
 	Debug bool
+	RoundsPerHour float64
+	Coverage *CoverageReport // nil değilse strateji hücre kullanımı toplanır
+	EVReport *EVAttributionReport // nil değilse (anahtar, aksiyon) bazında gerçekleşen EV toplanır
 }
 
-func NewEngine(cfg config.SimulationConfig, logger *Logger, showProgress bool, debug bool, stdinStrategies map[string]CountingStrategyFile) *Engine {
-	players := []*Player{}
-	boxes := make([]*Box, 7)
-	deck := NewDeck(cfg.NumDecks, ParseForcedCards(cfg.ForcedCards))
+// NewEngine, config ve oyuncuların stratejileriyle yeni bir masa kurar. stdinStrategies nil değilse
+// stratejiler (ve kalıtım zincirleri) yalnızca ondan, aksi halde strateji dizininden okunur.
+func NewEngine(cfg config.SimulationConfig, logger *Logger, showProgress bool, debug bool, stdinStrategies map[string]CountingStrategyFile) (*Engine, error) {
+	strategies, err := loadPlayerStrategies(cfg, stdinStrategies)
+	if err != nil {
+		return nil, err
+	}
+	return newEngine(cfg, logger, showProgress, debug, strategies)
+}
 
-	// Oyuncuları oluştur
+// loadPlayerStrategies, config'teki oyuncuların stratejilerini bir kez okur, extends/overlays'i çözer
+// ve doğrular. Dönen dosyalardan newStrategy ile doğrulama yapmadan taze stratejiler oluşturulur.
+func loadPlayerStrategies(cfg config.SimulationConfig, bundle map[string]CountingStrategyFile) (map[string]CountingStrategyFile, error) {
+	strategies := map[string]CountingStrategyFile{}
 	for _, pc := range cfg.Players {
-		var strategy Strategy
+		if _, ok := strategies[pc.Strategy]; ok {
+			continue
+		}
+		var data CountingStrategyFile
 		var err error
-
-		if stdinStrategies != nil {
-			data, ok := stdinStrategies[pc.Strategy]
+		if bundle != nil {
+			raw, ok := bundle[pc.Strategy]
 			if !ok {
-				fmt.Printf("Strategy %s not found in stdin input\n", pc.Strategy)
-				os.Exit(1)
+				return nil, fmt.Errorf("strategy %s not found in stdin input", pc.Strategy)
+			}
+			// extends/overlays yalnızca stdin ile verilen stratejilerden çözülür
+			data, err = ResolveStrategyFileFrom(pc.Strategy, raw, StrategyBundleSource(bundle))
+			if err == nil {
+				err = checkStrategyData(pc.Strategy, data)
 			}
-			strategy, err = LoadCountingStrategyFromData(pc.Strategy, data)
 			if err != nil {
-				fmt.Printf("Failed to load strategy from stdin data: %v\n", err)
-				os.Exit(1)
+				return nil, fmt.Errorf("failed to load strategy from stdin data: %w", err)
 			}
 		} else {
-			strategy, err = LoadStrategyFromFile(pc.Strategy)
+			data, err = ReadCountingStrategyFile(pc.Strategy)
+			if err == nil {
+				err = checkStrategyData(pc.Strategy, data)
+			}
 			if err != nil {
-				fmt.Printf("Failed to load strategy from file: %v\n", err)
-				os.Exit(1)
+				return nil, fmt.Errorf("failed to load strategy from file: %w", err)
 			}
 		}
+		strategies[pc.Strategy] = data
+	}
+	return strategies, nil
+}
+
+// newEngine, loadPlayerStrategies ile çözülüp doğrulanmış stratejilerle masayı kurar. Her çağrı
+// taze strateji örnekleri oluşturur; risk analizi denemeleri aynı dosyaları paylaşır.
+func newEngine(cfg config.SimulationConfig, logger *Logger, showProgress bool, debug bool, strategies map[string]CountingStrategyFile) (*Engine, error) {
+	players := []*Player{}
+	boxes := make([]*Box, 7)
+	var deck *Deck
+	if cfg.InfiniteDeck {
+		deck = NewInfiniteDeck(cfg.NumDecks, ParseForcedCards(cfg.ForcedCards))
+	} else {
+		deck = NewDeck(cfg.NumDecks, ParseForcedCards(cfg.ForcedCards))
+	}
+	if err := ValidateShuffleConfig(cfg.Shuffle); err != nil {
+		return nil, fmt.Errorf("invalid shuffle config: %w", err)
+	}
+	deck.Shuffle = cfg.Shuffle
+	if cfg.BurnCards > 0 && !cfg.InfiniteDeck && cfg.CSM == nil {
+		deck.BurnCards = cfg.BurnCards
+		deck.BurnFaceUp = cfg.BurnFaceUp
+	}
+	if cfg.CSM != nil && !cfg.InfiniteDeck {
+		deck.CSM = true
+		deck.CSMReturnDelay = cfg.CSM.ReturnDelayRounds
+	}
+
+	// Oyuncuları oluştur
+	for _, pc := range cfg.Players {
+		data, ok := strategies[pc.Strategy]
+		if !ok {
+			return nil, fmt.Errorf("strategy %s is not loaded", pc.Strategy)
+		}
+		strategy, err := newStrategy(pc.Strategy, data)
+		if err != nil {
+			return nil, err
+		}
 
-		if cs, ok := strategy.(*CountingStrategy); ok && cs.CountingEnabled {
-			cs.Deck = deck
+		if cs, ok := countingStrategyOf(strategy); ok && cs.CountingEnabled {
+			cs.AttachDeck(deck)
+			cs.holeCardDealt = cfg.DealerTakesHoleCard
 		}
 
 		p := NewPlayer(pc, strategy)
@@ -78,11 +136,22 @@ func NewEngine(cfg config.SimulationConfig, logger *Logger, showProgress bool, d
 				continue // aynı box'a iki kişi oturamaz
 			}
 			box := NewBoxWithConfig(b, p)
+			if cs, ok := countingStrategyOf(strategy); ok && cs.Progression != nil {
+				box.Progression, _ = NewBettingSystem(cs.Progression) // config yüklemede doğrulandı
+			}
 			boxes[idx] = box
 			p.Boxes = append(p.Boxes, box)
 		}
 	}
 
+	// İlk shoe NewDeck içinde karıştırıldı; sayıcılar bağlandıktan sonra yakma burada yapılır
+	deck.burn()
+
+	roundsPerHour := cfg.RoundsPerHour
+	if roundsPerHour <= 0 {
+		roundsPerHour = 60
+	}
+
 	return &Engine{
 		Deck:                deck,
 		Dealer:              NewDealer(),
@@ -91,6 +160,7 @@ func NewEngine(cfg config.SimulationConfig, logger *Logger, showProgress bool, d
 		RoundCount:          cfg.RoundCount,
 		HitOnSoft17:         cfg.HitOnSoft17,
 		AllowDAS:            cfg.AllowDoubleAfterSplit,
+		DoubleFirstTwoOnly:  cfg.DoubleFirstTwoOnly,
 		AllowSurrender:      cfg.AllowSurrender,
 		SurrenderAgainstAce: cfg.SurrenderAgainstAce, 
 		DealerTakesHoleCard: cfg.DealerTakesHoleCard,
@@ -105,29 +175,16 @@ func NewEngine(cfg config.SimulationConfig, logger *Logger, showProgress bool, d
 		MinSideBet:          cfg.MinBet / 5,
 		MaxSideBet:          cfg.MaxBet / 5,
 		Debug: 				 debug,
-	}
+		RoundsPerHour:       roundsPerHour,
+	}, nil
 }
 
 func (e *Engine) Run() {
 	for i := 0; i < e.RoundCount; i++ {
-		active := false
-		for _, p := range e.Players {
-			if !p.IsBusted && !p.IsRetired {
-				active = true
-				break
-			}
-		}
-		if !active {
+		if !e.Step() {
 			break
 		}
 
-		e.PlayRound()
-		e.CurrentRound++
-
-		if e.Deck.ShuffleIfNeeded() {
-			e.CurrentShoeNumber++
-		}
-
 		if e.ShowProgress && e.RoundCount > 0 {
 			percent := e.CurrentRound * 100 / e.RoundCount
 			if percent > 100 {
@@ -158,6 +215,35 @@ func (e *Engine) Run() {
 	}
 }
 
+// Step, tek bir round oynatır ve gerekirse shoe'yu karıştırır.
+// Oynamaya devam eden oyuncu kalmadıysa round oynanmaz ve false döner.
+func (e *Engine) Step() bool {
+	active := false
+	for _, p := range e.Players {
+		if !p.IsBusted && !p.IsRetired {
+			active = true
+			break
+		}
+	}
+	if !active {
+		return false
+	}
+
+	e.PlayRound()
+	e.CurrentRound++
+
+	if e.Deck.ShuffleIfNeeded() {
+		e.CurrentShoeNumber++
+		// Wonging oyuncuları karıştırmada masadan kalkar
+		for _, p := range e.Players {
+			if p.Wonging != nil && !p.Wonging.StayAfterShuffle {
+				p.IsSeated = false
+			}
+		}
+	}
+	return true
+}
+
 func (e *Engine) PlayRound() {
 	e.Deck.ResetRoundCounter()
 	e.Dealer.ResetHand()
@@ -165,10 +251,15 @@ func (e *Engine) PlayRound() {
 	for _, p := range e.Players {
 		if !p.IsBusted && !p.IsRetired {
 			p.ResetRound()  // ✔️ yalnızca bir kez! Oyunucun round bazlı başında ve sonundaki kasa bilgisi her hand için aynı olsun diye. 
+			p.UpdateWonging(e.playerTrueCount(p))
 		}
 	}
 
+	// Count'a göre box sayısını ayarlayan oyuncular için box'ları dağıt
+	e.assignSpreadBoxes()
+
 	// Box içeriğini sıfırla
+	dealtBoxes := 0 // bahsi yapılıp kart alacak box'lar; dağıtım aynı box sırasıyla yapılır
 	for _, box := range e.Boxes {
 		if box == nil || box.Player == nil || box.Player.IsBusted || box.Player.IsRetired {
 			continue
@@ -176,8 +267,26 @@ func (e *Engine) PlayRound() {
 		p := box.Player
 		box.Reset()
 
-		if cs, ok := p.Strategy.(*CountingStrategy); ok {
-			box.MainBet = cs.GetBetUnit(box.OriginalMainBet)
+		if p.IsWatching() || box.SittingOut {
+			continue // wonging oyuncusu bu round'u izliyor ya da spread bu box'ı kullanmıyor
+		}
+
+		if cs, ok := countingStrategyOf(p.Strategy); ok {
+			// Bakiyeye bağlı bahis modlarında oyuncunun round başı bakiyesi bu round oynadığı
+			// box'lar (spread ile ödünç alınanlar dahil) arasında paylaştırılır
+			bankroll := p.RoundStartBal / float64(e.activeBoxCount(p))
+			cs.dealOffset = dealtBoxes
+			box.MainBet = p.Strategy.(BetSizer).GetBetUnit(box.OriginalMainBet, bankroll)
+			if box.SpreadFraction > 0 {
+				box.MainBet *= box.SpreadFraction
+			}
+
+			// Progresyon sistemi, box'ın önceki sonuçlarına göre birim bahsi çarpar
+			if box.Progression != nil {
+				box.ProgressionUnit = cs.Progression.unitBet(box.OriginalMainBet, box.MainBet)
+				box.ProgressionState = box.Progression.State()
+				box.MainBet = box.ProgressionUnit * box.Progression.NextUnits()
+			}
 		}
 
 		// Stratejinin önerdiği bahsi masanın limitleri (MinBet/MaxBet) içinde kalacak şekilde ayarla.
@@ -212,6 +321,7 @@ func (e *Engine) PlayRound() {
 
 		hand := NewHand(box.MainBet, box.ID, box.nextHandID)
 		box.AddHand(hand)
+		dealtBoxes++
 	}
 
 	// İlk kart dağıtımı (her box'a)
@@ -240,6 +350,7 @@ func (e *Engine) PlayRound() {
 	if e.DealerTakesHoleCard {
 		dc2, _ := e.Deck.DealCard()
 		e.Dealer.Hand.AddCard(dc2)
+		e.revealHoleCard(dc2)
 	}
 
 	// Yan bahisleri değerlendir
@@ -278,11 +389,15 @@ func (e *Engine) PlayRound() {
 	anyInsuranceTaken := false
 	if dealerHasAce {
 		for _, box := range e.Boxes {
-			if box == nil || box.Player == nil || box.Player.IsBusted || box.Player.IsRetired {
+			if box == nil || box.Player == nil || box.Player.IsBusted || box.Player.IsRetired || len(box.Hands) == 0 {
 				continue
 			}
 			p := box.Player
-			if p.Strategy.DecideInsurance() {
+			takeInsurance := p.Strategy.DecideInsurance()
+			if p.HoleCard != nil {
+				takeInsurance = p.HoleCard.Value() == 10 // hole card'ı gören oyuncu sonucu bilir
+			}
+			if takeInsurance {
 				amount := box.MainBet / 2
 				if p.PlaceBet(amount) {
 					// sigorta başarıyla alındı
@@ -391,8 +506,9 @@ func (e *Engine) executeBoxActions() {
 				continue handLoop
 			}
 
-			actions, isFallback, isDeviation, key := p.Strategy.GetAction(hand, e.Dealer.Hand.Cards[0])
-			hand.SetDecisionTrace(actions) // Önerilen tüm eylemleri geçici olarak sakla
+			ctx := e.decisionContext(box, hand)
+			actions, isFallback, isDeviation, key := p.Strategy.GetAction(ctx)
+			hand.SetDecisionTrace(actions, ctx.LegalActions()) // Önerilen tüm eylemleri geçici olarak sakla
 
 			actionLoop:
 				for _, action := range actions {
@@ -403,13 +519,8 @@ func (e *Engine) executeBoxActions() {
 
 					switch action {
 					case "surrender":
-						// YENİ KONTROL: Dağıtıcının açık kartı As ise, kuralın izin verip vermediğine bak.
-						dealerHasAce := e.Dealer.Hand.Cards[0].Rank == "A"
-						if dealerHasAce && !e.SurrenderAgainstAce {
-							continue actionLoop // İzin yoksa, bu eylemi atla ve bir sonrakini dene.
-						}
-						// Surrender sadece ilk iki kartla ve kural izin veriyorsa mümkündür.
-						if e.AllowSurrender && len(hand.Cards) == 2  && !hand.IsSplitChild {
+						// Surrender sadece ilk iki kartla, kural izin veriyorsa (as karşısında ayrıca) mümkündür.
+						if e.canSurrender(hand) {
 							finalizeAndLog("surrender")
 							hand.Result = "surrender" // Elin sonucunu ayarla
 							i++                       // Sıradaki ele geç
@@ -419,7 +530,7 @@ func (e *Engine) executeBoxActions() {
 						continue actionLoop
 
 					case "split":
-						if hand.CanSplit() && len(box.Hands) < e.MaxSplits+1 && p.PlaceBet(hand.BetAmount) {
+						if e.canSplit(box, hand) && p.PlaceBet(hand.BetAmount) {
 							finalizeAndLog("split")
 							c1 := hand.Cards[0]
 							c2 := hand.Cards[1]
@@ -430,6 +541,8 @@ func (e *Engine) executeBoxActions() {
 							h1.AddCard(card1)
 							box.nextHandID++
 							h1.IsSplitChild = true
+							// h1 bölünen elin yerini alır, split kararı dahil izin sahipliği ona geçer
+							h1.InheritedDecisions = hand.InheritedDecisions
 
 							h2 := NewSplitHand(hand, box.nextHandID)
 							h2.AddCard(c2)
@@ -447,7 +560,7 @@ func (e *Engine) executeBoxActions() {
 						continue actionLoop 
 
 					case "double":
-						if !(hand.IsSplitChild && !e.AllowDAS) && p.PlaceBet(hand.BetAmount) {
+						if e.canDouble(hand) && p.PlaceBet(hand.BetAmount) {
 							finalizeAndLog("double")
 							hand.MarkAsDoubled()
 							card, _ := e.Deck.DealCard()
@@ -477,6 +590,25 @@ func (e *Engine) executeBoxActions() {
 }
 
 func (e *Engine) handleRoundEnd() {
+	// Bu round'da eli olan oyuncular oynamış, diğerleri (wonging ya da bahis yapamayan) izlemiş sayılır
+	for _, p := range e.Players {
+		if p.IsBusted || p.IsRetired {
+			continue
+		}
+		played := false
+		for _, box := range p.Boxes {
+			if box.Player == p && len(box.Hands) > 0 {
+				played = true
+				break
+			}
+		}
+		if played {
+			p.RoundsPlayed++
+		} else {
+			p.RoundsWatched++
+		}
+	}
+
 	for _, box := range e.Boxes {
 		if box == nil || box.Player == nil {
 			continue
@@ -512,6 +644,22 @@ func (e *Engine) handleRoundEnd() {
 
 		// Ödemeyi yap
 		box.Player.ReceivePayout(box.TotalPayout)
+
+		if e.Coverage != nil {
+			e.Coverage.RecordBox(box)
+		}
+		if e.EVReport != nil {
+			e.EVReport.RecordBox(box)
+		}
+
+		// Progresyon yalnızca ana ellerin sonucuna bakar (yan bahis ve sigorta hariç)
+		if box.Progression != nil && len(box.Hands) > 0 && box.ProgressionUnit > 0 {
+			mainNet := 0.0
+			for _, hand := range box.Hands {
+				mainNet += hand.Payout - hand.BetAmount
+			}
+			box.Progression.Record(mainNet / box.ProgressionUnit)
+		}
 	}
 
 	for _, p := range e.Players {
@@ -564,10 +712,10 @@ func (e *Engine) handleDealerBlackjackInsurance() {
 
 		for _, hand := range box.Hands {
 			if hand.IsBlackjack() {
-				hand.FinalizeDecision("No Decision", "Player Blackjack", false, false)
+				hand.FinalizeDecision(NoDecisionKey, "Player Blackjack", false, false)
 				hand.Result = "push"
 			} else {
-				hand.FinalizeDecision("No Decision", "Dealer Blackjack", false, false)
+				hand.FinalizeDecision(NoDecisionKey, "Dealer Blackjack", false, false)
 				hand.Result = "lose"
 			}
 		}
@@ -646,4 +794,46 @@ func (e *Engine) determineAndPlaceBets(box *Box) bool {
 	box.P21Bet = finalP21Bet
 
 	return true // Bahis başarıyla yapıldı.
-}
\ No newline at end of file
+}
+// visibleCards, oyuncunun karar anında masada görebildiği kartları toplar
+func (e *Engine) visibleCards(p *Player, current *Hand) *VisibleCards {
+	v := &VisibleCards{
+		HoleCard:       p.HoleCard,
+		Burned:         e.Deck.VisibleBurned(),
+		PreviousRounds: e.Deck.PreviousRoundCards(),
+	}
+	for _, box := range e.Boxes {
+		if box == nil {
+			continue
+		}
+		for _, h := range box.Hands {
+			if h != current && len(h.Cards) > 0 {
+				v.OtherHands = append(v.OtherHands, h.Cards)
+			}
+		}
+	}
+	return v
+}
+
+// revealHoleCard, dealer'ın hole card'ı gösterme ihtimali olan oyunculardan her biri için
+// olasılığa göre kartı o oyuncuya açar. Round'u izleyen oyuncular kartı görmez.
+func (e *Engine) revealHoleCard(hole Card) {
+	for _, p := range e.Players {
+		if p.HoleCardProbability <= 0 || p.IsBusted || p.IsRetired || p.IsWatching() {
+			continue
+		}
+		if rand.Float64() < p.HoleCardProbability {
+			card := hole
+			p.HoleCard = &card
+		}
+	}
+}
+
+// playerTrueCount, oyuncunun stratejisinin bahis için gördüğü true count'u döndürür (side count düzeltmesi dahil).
+// Sayma yapmayan stratejilerde shoe'nun gerçek true count'u kullanılır.
+func (e *Engine) playerTrueCount(p *Player) float64 {
+	if cs, ok := countingStrategyOf(p.Strategy); ok && cs.Deck != nil {
+		return cs.bettingTrueCount()
+	}
+	return e.Deck.TrueCount()
+}
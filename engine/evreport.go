@@ -0,0 +1,248 @@
+package engine
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ActionEV, bir anahtarda aynı yasal aksiyonlarla verilmiş kararlarda tek bir nihai aksiyon için
+// gerçekleşen sonuçların birim bahis cinsinden özetidir.
+type ActionEV struct {
+	Strategy   string  `json:"strategy"`
+	Key        string  `json:"key"`
+	Legal      string  `json:"legal_actions"` // karar anında yasal aksiyonlar ("double/hit/stand")
+	Action     string  `json:"action"`
+	ListedRank int     `json:"listed_rank"` // stratejinin yasal aksiyonlar arasındaki en sık sırası (0: birincil, -1: listede yok)
+	Count      int     `json:"count"`
+	sum        float64 // birim bahis başına net sonuçların toplamı
+	sumSq      float64
+	ranks      map[int]int // liste sırası -> karar sayısı
+}
+
+// EV, kararın birim bahis başına ortalama net sonucudur
+func (a *ActionEV) EV() float64 {
+	if a.Count == 0 {
+		return 0
+	}
+	return a.sum / float64(a.Count)
+}
+
+// StdErr, ortalama EV'nin standart hatasıdır
+func (a *ActionEV) StdErr() float64 {
+	if a.Count < 2 {
+		return 0
+	}
+	mean := a.EV()
+	variance := (a.sumSq - float64(a.Count)*mean*mean) / float64(a.Count-1)
+	if variance < 0 {
+		variance = 0
+	}
+	return math.Sqrt(variance / float64(a.Count))
+}
+
+// EVAttributionReport, her el sonucunu karar izindeki kararlara geri dağıtarak
+// (anahtar, nihai aksiyon) çiftlerinin gerçekleşen EV'sini toplar.
+// Split kararına tüm çocuk ellerin sonucu, double kararına ise iki katlanmış bahsin sonucu yazılır.
+type EVAttributionReport struct {
+	entries map[string]*ActionEV
+}
+
+func NewEVAttributionReport() *EVAttributionReport {
+	return &EVAttributionReport{entries: map[string]*ActionEV{}}
+}
+
+// decisionOutcome, round içinde tek bir kararın toplanan sonucudur
+type decisionOutcome struct {
+	entry DecisionLogEntry
+	net   float64
+	unit  float64
+}
+
+// RecordBox, round sonunda (payout'lar hesaplandıktan sonra) box'taki kararların sonuçlarını işler.
+func (r *EVAttributionReport) RecordBox(box *Box) {
+	if box == nil || box.Player == nil {
+		return
+	}
+	strategy := box.Player.Strategy.String()
+
+	// Split çocukları üst elin kararlarını kopyalar; aynı karar (HandID, sıra) ile tanınır
+	// ve tüm çocukların sonucu o karara toplanır.
+	order := []string{}
+	outcomes := map[string]*decisionOutcome{}
+	for _, hand := range box.Hands {
+		net := hand.Payout - hand.BetAmount
+		for i, d := range hand.DecisionTrace {
+			if d.Key == NoDecisionKey {
+				continue
+			}
+			id := fmt.Sprintf("%s#%d", d.HandID, i)
+			o, ok := outcomes[id]
+			if !ok {
+				o = &decisionOutcome{entry: d, unit: hand.InitialBet}
+				outcomes[id] = o
+				order = append(order, id)
+			}
+			o.net += net
+		}
+	}
+
+	for _, id := range order {
+		o := outcomes[id]
+		if o.unit <= 0 {
+			continue
+		}
+		a := r.entry(strategy, o.entry)
+		units := o.net / o.unit
+		a.Count++
+		a.sum += units
+		a.sumSq += units * units
+	}
+}
+
+func (r *EVAttributionReport) entry(strategy string, d DecisionLogEntry) *ActionEV {
+	legal := strings.Join(d.Legal, "/")
+	id := strategy + "|" + d.Key + "|" + legal + "|" + d.FinalAction
+	a, ok := r.entries[id]
+	if !ok {
+		a = &ActionEV{Strategy: strategy, Key: d.Key, Legal: legal, Action: d.FinalAction, ranks: map[int]int{}}
+		r.entries[id] = a
+	}
+	a.ranks[listedRank(d)]++
+	return a
+}
+
+// listedRank, nihai aksiyonun stratejinin önerdiği listede yasal aksiyonlar arasındaki sırasıdır;
+// double yapılamayan bir elde "double, hit" listesindeki hit birincil (0) sayılır.
+func listedRank(d DecisionLogEntry) int {
+	rank := 0
+	for _, action := range d.Actions {
+		if action == d.FinalAction {
+			return rank
+		}
+		for _, l := range d.Legal {
+			if l == action {
+				rank++
+				break
+			}
+		}
+	}
+	return -1
+}
+
+// mostCommonRank, aksiyonun en çok kararda sahip olduğu liste sırasıdır (eşitlikte küçük olan)
+func (a *ActionEV) mostCommonRank() int {
+	best, bestCount := -1, 0
+	for rank, count := range a.ranks {
+		if count > bestCount || (count == bestCount && rankLess(rank, best)) {
+			best, bestCount = rank, count
+		}
+	}
+	return best
+}
+
+// rankLess, listede yer alan sıraları (>= 0) listede olmayandan (-1) önce sıralar
+func rankLess(a, b int) bool {
+	if a < 0 || b < 0 {
+		return a > b
+	}
+	return a < b
+}
+
+// ActionEVRow, rapor satırıdır: aksiyonun EV'si, aynı anahtarda aynı yasal aksiyonlarla verilmiş
+// kararlarda gözlenen en iyi alternatifle karşılaştırılır.
+type ActionEVRow struct {
+	*ActionEV
+	EV              float64 `json:"ev"`
+	StdErr          float64 `json:"std_err"`
+	BestAlternative string  `json:"best_alternative"`
+	AlternativeEV   float64 `json:"alternative_ev"`
+	Underperforms   bool    `json:"underperforms"` // alternatif iki standart hatadan fazla daha iyi
+}
+
+// Rows, raporu strateji, anahtar, yasal aksiyonlar ve liste sırasına göre sıralı döndürür.
+// Aksiyonlar yalnızca aynı yasal aksiyonlarla verilmiş kararlar arasında karşılaştırılır; üç kartlık
+// bir hit, double'ın yasal olduğu iki kartlık ellerdeki double ile kıyaslanmaz.
+func (r *EVAttributionReport) Rows() []ActionEVRow {
+	byKey := map[string][]*ActionEV{}
+	for _, a := range r.entries {
+		a.ListedRank = a.mostCommonRank()
+		id := a.Strategy + "|" + a.Key + "|" + a.Legal
+		byKey[id] = append(byKey[id], a)
+	}
+
+	rows := []ActionEVRow{}
+	for _, id := range sortedKeys(byKey) {
+		actions := byKey[id]
+		sort.Slice(actions, func(i, j int) bool {
+			if ri, rj := actions[i].ListedRank, actions[j].ListedRank; ri != rj {
+				return rankLess(ri, rj)
+			}
+			return actions[i].Action < actions[j].Action
+		})
+		for _, a := range actions {
+			row := ActionEVRow{ActionEV: a, EV: a.EV(), StdErr: a.StdErr()}
+			var best *ActionEV
+			for _, alt := range actions {
+				if alt != a && (best == nil || alt.EV() > best.EV()) {
+					best = alt
+				}
+			}
+			if best != nil {
+				row.BestAlternative = best.Action
+				row.AlternativeEV = best.EV()
+				margin := 2 * math.Sqrt(a.StdErr()*a.StdErr()+best.StdErr()*best.StdErr())
+				row.Underperforms = best.Count > 1 && a.Count > 1 && best.EV()-a.EV() > margin
+			}
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+// WriteFile, raporu dosya uzantısına göre JSON ya da CSV olarak yazar
+func (r *EVAttributionReport) WriteFile(path string) error {
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		out, err := json.MarshalIndent(r.Rows(), "", "  ")
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(path, out, 0644)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := csv.NewWriter(f)
+	w.Write([]string{"strategy", "key", "legal_actions", "action", "listed_rank", "count", "ev", "std_err", "best_alternative", "alternative_ev", "underperforms"})
+	for _, row := range r.Rows() {
+		alternativeEV := ""
+		if row.BestAlternative != "" {
+			alternativeEV = fmt.Sprintf("%.4f", row.AlternativeEV)
+		}
+		w.Write([]string{
+			row.Strategy,
+			row.Key,
+			row.Legal,
+			row.Action,
+			strconv.Itoa(row.ListedRank),
+			strconv.Itoa(row.Count),
+			fmt.Sprintf("%.4f", row.EV),
+			fmt.Sprintf("%.4f", row.StdErr),
+			row.BestAlternative,
+			alternativeEV,
+			boolToStr(row.Underperforms),
+		})
+	}
+	w.Flush()
+	return w.Error()
+}
@@ -0,0 +1,78 @@
+package engine
+
+import (
+	"fmt"
+	"testing"
+)
+
+// evTestBox, verilen kararlarla oynanmış ve net sonucu birim bahis cinsinden verilen tek elli bir box oluşturur
+func evTestBox(id string, net float64, trace ...DecisionLogEntry) *Box {
+	hand := &Hand{ID: id, BetAmount: 1, InitialBet: 1, Payout: 1 + net, DecisionTrace: trace}
+	for i := range hand.DecisionTrace {
+		hand.DecisionTrace[i].HandID = id
+	}
+	return &Box{Player: &Player{Strategy: &CountingStrategy{Name: "test", BaseStrategy: &DynamicStrategy{}}}, Hands: []*Hand{hand}}
+}
+
+// Double'ın yasal olmadığı üç kartlık eldeki hit, iki kartlık ellerdeki double ile kıyaslanmamalıdır
+func TestEVAttributionComparesSameLegalActions(t *testing.T) {
+	twoCards := []string{"double", "hit", "stand"}
+	threeCards := []string{"hit", "stand"}
+	strategyActions := []string{"double", "hit"}
+	r := NewEVAttributionReport()
+	for i := 0; i < 50; i++ {
+		id := fmt.Sprint(i)
+		r.RecordBox(evTestBox(id+"d", float64(2*(i%2)), DecisionLogEntry{Key: "hard_10_vs_3", Actions: strategyActions, FinalAction: "double", Legal: twoCards}))
+		r.RecordBox(evTestBox(id+"h", float64(i%2-1), DecisionLogEntry{Key: "hard_10_vs_3", Actions: strategyActions, FinalAction: "hit", Legal: threeCards}))
+	}
+
+	rows := r.Rows()
+	if len(rows) != 2 {
+		t.Fatalf("got %d rows, want 2: %+v", len(rows), rows)
+	}
+	for _, row := range rows {
+		if row.BestAlternative != "" || row.Underperforms {
+			t.Errorf("%s (%s) compared with %q, want no alternative", row.Action, row.Legal, row.BestAlternative)
+		}
+		// Yasal aksiyonlar arasında hit de double da birincil öneridir
+		if row.ListedRank != 0 {
+			t.Errorf("%s (%s): listed rank %d, want 0", row.Action, row.Legal, row.ListedRank)
+		}
+	}
+}
+
+func TestEVAttributionFlagsUnderperformingAction(t *testing.T) {
+	legal := []string{"hit", "stand"}
+	r := NewEVAttributionReport()
+	for i := 0; i < 40; i++ {
+		id := fmt.Sprint(i)
+		r.RecordBox(evTestBox(id+"s", -1, DecisionLogEntry{Key: "hard_16_vs_10", Actions: []string{"stand"}, FinalAction: "stand", Legal: legal}))
+		r.RecordBox(evTestBox(id+"h", float64(i%2), DecisionLogEntry{Key: "hard_16_vs_10", Actions: []string{"hit"}, FinalAction: "hit", Legal: legal, IsDeviation: true}))
+	}
+	for _, row := range r.Rows() {
+		if want := row.Action == "stand"; row.Underperforms != want {
+			t.Errorf("%s: underperforms %v, want %v", row.Action, row.Underperforms, want)
+		}
+	}
+}
+
+func TestListedRank(t *testing.T) {
+	tests := []struct {
+		actions []string
+		final   string
+		legal   []string
+		want    int
+	}{
+		{[]string{"double", "hit"}, "double", []string{"double", "hit", "stand"}, 0},
+		{[]string{"double", "hit"}, "hit", []string{"double", "hit", "stand"}, 1},
+		{[]string{"double", "hit"}, "hit", []string{"hit", "stand"}, 0},
+		{[]string{"surrender", "double", "stand"}, "stand", []string{"hit", "stand"}, 0},
+		{[]string{"hit"}, "stand", []string{"hit", "stand"}, -1},
+	}
+	for _, tt := range tests {
+		d := DecisionLogEntry{Actions: tt.actions, FinalAction: tt.final, Legal: tt.legal}
+		if got := listedRank(d); got != tt.want {
+			t.Errorf("listedRank(%v, %s, legal %v) = %d, want %d", tt.actions, tt.final, tt.legal, got, tt.want)
+		}
+	}
+}
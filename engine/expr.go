@@ -0,0 +1,547 @@
+package engine
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Script strateji ifadeleri için küçük bir ifade dili. Örnek:
+//
+//	tc >= 3 && hand.total == 16 ? "stand" : base()
+//
+// Değer türleri sayı, string ve bool'dur. Desteklenen operatörler (öncelik sırasıyla):
+// ?:, ||, &&, == !=, < <= > >=, + -, * / %, tekli ! ve -. İfadeler yüklemede ayrıştırılır ve
+// tür denetiminden geçer; böylece simülasyon sırasında tür hatası oluşmaz. Sıfıra bölme (/ ve %)
+// NaN/Inf üretmek yerine 0 verir.
+
+type exprType int
+
+const (
+	typeNumber exprType = iota
+	typeString
+	typeBool
+)
+
+func (t exprType) String() string {
+	switch t {
+	case typeNumber:
+		return "number"
+	case typeString:
+		return "string"
+	}
+	return "bool"
+}
+
+// exprValue, değerlendirme sonucudur; yalnızca türüne ait alan anlamlıdır
+type exprValue struct {
+	typ exprType
+	num float64
+	str string
+	b   bool
+}
+
+func numberValue(v float64) exprValue { return exprValue{typ: typeNumber, num: v} }
+func stringValue(v string) exprValue  { return exprValue{typ: typeString, str: v} }
+func boolValue(v bool) exprValue      { return exprValue{typ: typeBool, b: v} }
+
+// exprFunc, ifadelerden çağrılabilen bir fonksiyondur
+type exprFunc struct {
+	params []exprType
+	result exprType
+	call   func(args []exprValue) exprValue
+}
+
+// exprEnv, değişkenlerin ve fonksiyonların türlerini (derleme) ve değerlerini (çalışma) tutar
+type exprEnv struct {
+	vars  map[string]exprValue
+	funcs map[string]exprFunc
+}
+
+// exprNode, ayrıştırılmış ifade ağacının bir düğümüdür
+type exprNode interface {
+	check(env *exprEnv) (exprType, error)
+	eval(env *exprEnv) exprValue
+}
+
+// --- Lexer ---
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokNumber
+	tokString
+	tokIdent
+	tokOp
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+func tokenize(src string) ([]token, error) {
+	tokens := []token{}
+	i := 0
+	for i < len(src) {
+		c := rune(src[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case unicode.IsDigit(c) || (c == '.' && i+1 < len(src) && unicode.IsDigit(rune(src[i+1]))):
+			start := i
+			for i < len(src) && (unicode.IsDigit(rune(src[i])) || src[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, token{tokNumber, src[start:i], start})
+		case unicode.IsLetter(c) || c == '_':
+			start := i
+			for i < len(src) && (unicode.IsLetter(rune(src[i])) || unicode.IsDigit(rune(src[i])) || src[i] == '_' || src[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, token{tokIdent, src[start:i], start})
+		case c == '"' || c == '\'':
+			start := i
+			i++
+			for i < len(src) && rune(src[i]) != c {
+				i++
+			}
+			if i >= len(src) {
+				return nil, fmt.Errorf("unterminated string at %d", start)
+			}
+			tokens = append(tokens, token{tokString, src[start+1 : i], start})
+			i++
+		default:
+			two := ""
+			if i+1 < len(src) {
+				two = src[i : i+2]
+			}
+			switch two {
+			case "&&", "||", "==", "!=", "<=", ">=":
+				tokens = append(tokens, token{tokOp, two, i})
+				i += 2
+				continue
+			}
+			if strings.ContainsRune("+-*/%<>!?:(),", c) {
+				tokens = append(tokens, token{tokOp, string(c), i})
+				i++
+				continue
+			}
+			return nil, fmt.Errorf("unexpected character %q at %d", c, i)
+		}
+	}
+	return append(tokens, token{tokEOF, "", len(src)}), nil
+}
+
+// --- Parser ---
+
+type exprParser struct {
+	tokens []token
+	pos    int
+}
+
+// parseExpr, kaynak metni ifade ağacına çevirir
+func parseExpr(src string) (exprNode, error) {
+	tokens, err := tokenize(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &exprParser{tokens: tokens}
+	node, err := p.ternary()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, fmt.Errorf("unexpected %q at %d", t.text, t.pos)
+	}
+	return node, nil
+}
+
+func (p *exprParser) peek() token { return p.tokens[p.pos] }
+
+func (p *exprParser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *exprParser) acceptOp(ops ...string) (string, bool) {
+	t := p.peek()
+	if t.kind != tokOp {
+		return "", false
+	}
+	for _, op := range ops {
+		if t.text == op {
+			p.pos++
+			return op, true
+		}
+	}
+	return "", false
+}
+
+func (p *exprParser) expectOp(op string) error {
+	if _, ok := p.acceptOp(op); !ok {
+		t := p.peek()
+		return fmt.Errorf("expected %q at %d, got %q", op, t.pos, t.text)
+	}
+	return nil
+}
+
+func (p *exprParser) ternary() (exprNode, error) {
+	cond, err := p.binary(0)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := p.acceptOp("?"); !ok {
+		return cond, nil
+	}
+	then, err := p.ternary()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expectOp(":"); err != nil {
+		return nil, err
+	}
+	otherwise, err := p.ternary()
+	if err != nil {
+		return nil, err
+	}
+	return &ternaryNode{cond, then, otherwise}, nil
+}
+
+// binaryLevels, ikili operatörlerin düşükten yükseğe öncelik sırasıdır
+var binaryLevels = [][]string{
+	{"||"},
+	{"&&"},
+	{"==", "!="},
+	{"<", "<=", ">", ">="},
+	{"+", "-"},
+	{"*", "/", "%"},
+}
+
+func (p *exprParser) binary(level int) (exprNode, error) {
+	if level == len(binaryLevels) {
+		return p.unary()
+	}
+	left, err := p.binary(level + 1)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.acceptOp(binaryLevels[level]...)
+		if !ok {
+			return left, nil
+		}
+		right, err := p.binary(level + 1)
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op, left, right}
+	}
+}
+
+func (p *exprParser) unary() (exprNode, error) {
+	if op, ok := p.acceptOp("!", "-"); ok {
+		operand, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return &unaryNode{op, operand}, nil
+	}
+	return p.primary()
+}
+
+func (p *exprParser) primary() (exprNode, error) {
+	t := p.next()
+	switch t.kind {
+	case tokNumber:
+		v, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q at %d", t.text, t.pos)
+		}
+		return &literalNode{numberValue(v)}, nil
+	case tokString:
+		return &literalNode{stringValue(t.text)}, nil
+	case tokIdent:
+		switch t.text {
+		case "true":
+			return &literalNode{boolValue(true)}, nil
+		case "false":
+			return &literalNode{boolValue(false)}, nil
+		}
+		if _, ok := p.acceptOp("("); !ok {
+			return &varNode{t.text}, nil
+		}
+		call := &callNode{name: t.text}
+		if _, ok := p.acceptOp(")"); ok {
+			return call, nil
+		}
+		for {
+			arg, err := p.ternary()
+			if err != nil {
+				return nil, err
+			}
+			call.args = append(call.args, arg)
+			if _, ok := p.acceptOp(","); !ok {
+				break
+			}
+		}
+		if err := p.expectOp(")"); err != nil {
+			return nil, err
+		}
+		return call, nil
+	case tokOp:
+		if t.text == "(" {
+			inner, err := p.ternary()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expectOp(")"); err != nil {
+				return nil, err
+			}
+			return inner, nil
+		}
+	}
+	if t.kind == tokEOF {
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+	return nil, fmt.Errorf("unexpected %q at %d", t.text, t.pos)
+}
+
+// --- Nodes ---
+
+type literalNode struct{ value exprValue }
+
+func (n *literalNode) check(env *exprEnv) (exprType, error) { return n.value.typ, nil }
+func (n *literalNode) eval(env *exprEnv) exprValue          { return n.value }
+
+type varNode struct{ name string }
+
+func (n *varNode) check(env *exprEnv) (exprType, error) {
+	v, ok := env.vars[n.name]
+	if !ok {
+		return 0, fmt.Errorf("unknown variable %q", n.name)
+	}
+	return v.typ, nil
+}
+
+func (n *varNode) eval(env *exprEnv) exprValue { return env.vars[n.name] }
+
+type callNode struct {
+	name   string
+	args   []exprNode
+	values []exprValue // argüman değerleri için her değerlendirmede yeniden kullanılan alan
+}
+
+func (n *callNode) check(env *exprEnv) (exprType, error) {
+	f, ok := env.funcs[n.name]
+	if !ok {
+		return 0, fmt.Errorf("unknown function %q", n.name)
+	}
+	if len(n.args) != len(f.params) {
+		return 0, fmt.Errorf("%s() takes %d argument(s), got %d", n.name, len(f.params), len(n.args))
+	}
+	for i, arg := range n.args {
+		t, err := arg.check(env)
+		if err != nil {
+			return 0, err
+		}
+		if t != f.params[i] {
+			return 0, fmt.Errorf("%s() argument %d must be %s, got %s", n.name, i+1, f.params[i], t)
+		}
+	}
+	return f.result, nil
+}
+
+func (n *callNode) eval(env *exprEnv) exprValue {
+	if n.values == nil {
+		n.values = make([]exprValue, len(n.args))
+	}
+	for i, arg := range n.args {
+		n.values[i] = arg.eval(env)
+	}
+	return env.funcs[n.name].call(n.values)
+}
+
+type unaryNode struct {
+	op      string
+	operand exprNode
+}
+
+func (n *unaryNode) check(env *exprEnv) (exprType, error) {
+	t, err := n.operand.check(env)
+	if err != nil {
+		return 0, err
+	}
+	want := typeNumber
+	if n.op == "!" {
+		want = typeBool
+	}
+	if t != want {
+		return 0, fmt.Errorf("operator %s needs %s, got %s", n.op, want, t)
+	}
+	return t, nil
+}
+
+func (n *unaryNode) eval(env *exprEnv) exprValue {
+	v := n.operand.eval(env)
+	if n.op == "!" {
+		return boolValue(!v.b)
+	}
+	return numberValue(-v.num)
+}
+
+type binaryNode struct {
+	op          string
+	left, right exprNode
+}
+
+func (n *binaryNode) check(env *exprEnv) (exprType, error) {
+	lt, err := n.left.check(env)
+	if err != nil {
+		return 0, err
+	}
+	rt, err := n.right.check(env)
+	if err != nil {
+		return 0, err
+	}
+	if lt != rt {
+		return 0, fmt.Errorf("operator %s needs operands of the same type, got %s and %s", n.op, lt, rt)
+	}
+	switch n.op {
+	case "&&", "||":
+		if lt != typeBool {
+			return 0, fmt.Errorf("operator %s needs bool, got %s", n.op, lt)
+		}
+		return typeBool, nil
+	case "==", "!=":
+		return typeBool, nil
+	case "<", "<=", ">", ">=":
+		if lt != typeNumber {
+			return 0, fmt.Errorf("operator %s needs number, got %s", n.op, lt)
+		}
+		return typeBool, nil
+	case "+":
+		if lt == typeBool {
+			return 0, fmt.Errorf("operator + needs number or string, got bool")
+		}
+		return lt, nil
+	}
+	if lt != typeNumber {
+		return 0, fmt.Errorf("operator %s needs number, got %s", n.op, lt)
+	}
+	return typeNumber, nil
+}
+
+func (n *binaryNode) eval(env *exprEnv) exprValue {
+	l := n.left.eval(env)
+	// && ve || kısa devre yapar
+	switch n.op {
+	case "&&":
+		if !l.b {
+			return l
+		}
+		return n.right.eval(env)
+	case "||":
+		if l.b {
+			return l
+		}
+		return n.right.eval(env)
+	}
+	r := n.right.eval(env)
+	switch n.op {
+	case "==":
+		return boolValue(l == r)
+	case "!=":
+		return boolValue(l != r)
+	case "<":
+		return boolValue(l.num < r.num)
+	case "<=":
+		return boolValue(l.num <= r.num)
+	case ">":
+		return boolValue(l.num > r.num)
+	case ">=":
+		return boolValue(l.num >= r.num)
+	case "+":
+		if l.typ == typeString {
+			return stringValue(l.str + r.str)
+		}
+		return numberValue(l.num + r.num)
+	case "-":
+		return numberValue(l.num - r.num)
+	case "*":
+		return numberValue(l.num * r.num)
+	}
+	if r.num == 0 {
+		return numberValue(0) // sıfıra bölme
+	}
+	if n.op == "/" {
+		return numberValue(l.num / r.num)
+	}
+	return numberValue(math.Mod(l.num, r.num))
+}
+
+type ternaryNode struct {
+	cond, then, otherwise exprNode
+}
+
+func (n *ternaryNode) check(env *exprEnv) (exprType, error) {
+	ct, err := n.cond.check(env)
+	if err != nil {
+		return 0, err
+	}
+	if ct != typeBool {
+		return 0, fmt.Errorf("condition of ?: must be bool, got %s", ct)
+	}
+	tt, err := n.then.check(env)
+	if err != nil {
+		return 0, err
+	}
+	ot, err := n.otherwise.check(env)
+	if err != nil {
+		return 0, err
+	}
+	if tt != ot {
+		return 0, fmt.Errorf("branches of ?: must have the same type, got %s and %s", tt, ot)
+	}
+	return tt, nil
+}
+
+func (n *ternaryNode) eval(env *exprEnv) exprValue {
+	if n.cond.eval(env).b {
+		return n.then.eval(env)
+	}
+	return n.otherwise.eval(env)
+}
+
+// compileExpr, ifadeyi ayrıştırır ve sonucunun want türünde olduğunu denetler
+func compileExpr(src string, env *exprEnv, want exprType) (exprNode, error) {
+	node, err := parseExpr(src)
+	if err != nil {
+		return nil, err
+	}
+	t, err := node.check(env)
+	if err != nil {
+		return nil, err
+	}
+	if t != want {
+		return nil, fmt.Errorf("expression must return %s, got %s", want, t)
+	}
+	return node, nil
+}
+
+// mathFuncs, tüm ifadelerde kullanılabilen sayısal fonksiyonlardır
+var mathFuncs = map[string]exprFunc{
+	"min":   {[]exprType{typeNumber, typeNumber}, typeNumber, func(a []exprValue) exprValue { return numberValue(math.Min(a[0].num, a[1].num)) }},
+	"max":   {[]exprType{typeNumber, typeNumber}, typeNumber, func(a []exprValue) exprValue { return numberValue(math.Max(a[0].num, a[1].num)) }},
+	"abs":   {[]exprType{typeNumber}, typeNumber, func(a []exprValue) exprValue { return numberValue(math.Abs(a[0].num)) }},
+	"floor": {[]exprType{typeNumber}, typeNumber, func(a []exprValue) exprValue { return numberValue(math.Floor(a[0].num)) }},
+	"ceil":  {[]exprType{typeNumber}, typeNumber, func(a []exprValue) exprValue { return numberValue(math.Ceil(a[0].num)) }},
+	"round": {[]exprType{typeNumber}, typeNumber, func(a []exprValue) exprValue { return numberValue(math.Round(a[0].num)) }},
+}
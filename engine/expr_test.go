@@ -0,0 +1,160 @@
+package engine
+
+import (
+	"strings"
+	"testing"
+)
+
+// testExprEnv, testlerde kullanılan sabit değerli bir ifade ortamı oluşturur
+func testExprEnv() *exprEnv {
+	env := &exprEnv{vars: map[string]exprValue{
+		"tc":         numberValue(3),
+		"hand.total": numberValue(16),
+		"hand.soft":  boolValue(false),
+		"key":        stringValue("hard_16_vs_10"),
+	}, funcs: map[string]exprFunc{}}
+	for name, f := range mathFuncs {
+		env.funcs[name] = f
+	}
+	return env
+}
+
+func TestExprEval(t *testing.T) {
+	tests := []struct {
+		src  string
+		want exprValue
+	}{
+		{"1 + 2 * 3", numberValue(7)},
+		{"(1 + 2) * 3", numberValue(9)},
+		{"10 - 4 - 3", numberValue(3)},
+		{"12 / 3 / 2", numberValue(2)},
+		{"-2 * 3 + 10 % 4", numberValue(-4)},
+		{"2 + 3 > 4", boolValue(true)},
+		{"!false && 1 < 2", boolValue(true)},
+		{"true || false && false", boolValue(true)},
+		{"!(true || false) && true", boolValue(false)},
+		{"tc >= 3 ? 4 : 1 + 1", numberValue(4)},
+		{"false ? 1 : tc > 5 ? 2 : 3", numberValue(3)},
+		{"hand.total == 16 && !hand.soft", boolValue(true)},
+		{"key == 'hard_16_vs_10' ? \"stand\" : \"hit\"", stringValue("stand")},
+		{"max(1, min(tc * 2, 5))", numberValue(5)},
+		{"floor(tc / 2)", numberValue(1)},
+		{"5 / 0", numberValue(0)},
+		{"5 % 0", numberValue(0)},
+		{"tc / (tc - 3) + 1", numberValue(1)},
+	}
+	for _, tt := range tests {
+		env := testExprEnv()
+		node, err := parseExpr(tt.src)
+		if err != nil {
+			t.Errorf("%s: parse error: %v", tt.src, err)
+			continue
+		}
+		typ, err := node.check(env)
+		if err != nil {
+			t.Errorf("%s: check error: %v", tt.src, err)
+			continue
+		}
+		if typ != tt.want.typ {
+			t.Errorf("%s: type %s, want %s", tt.src, typ, tt.want.typ)
+		}
+		if got := node.eval(env); got != tt.want {
+			t.Errorf("%s = %+v, want %+v", tt.src, got, tt.want)
+		}
+	}
+}
+
+func TestCompileExprErrors(t *testing.T) {
+	tests := []struct {
+		src  string
+		want exprType
+		err  string
+	}{
+		{"1 +", typeNumber, ""},
+		{"(1 + 2", typeNumber, ""},
+		{"1 2", typeNumber, ""},
+		{"'open", typeString, ""},
+		{"1 + true", typeNumber, "needs"},
+		{"'a' < 'b'", typeBool, "needs"},
+		{"!1", typeBool, "operator ! needs bool"},
+		{"-hand.soft", typeNumber, "operator - needs number"},
+		{"1 ? 2 : 3", typeNumber, ""},
+		{"true ? 1 : 'x'", typeNumber, ""},
+		{"1 == true", typeBool, ""},
+		{"count + 1", typeNumber, ""},
+		{"sqrt(4)", typeNumber, `unknown function "sqrt"`},
+		{"min(1)", typeNumber, "min() takes 2 argument(s), got 1"},
+		{"abs(key)", typeNumber, "abs() argument 1 must be number, got string"},
+		{"tc + 1", typeBool, "expression must return bool, got number"},
+	}
+	for _, tt := range tests {
+		_, err := compileExpr(tt.src, testExprEnv(), tt.want)
+		if err == nil {
+			t.Errorf("compileExpr(%q) succeeded, want error", tt.src)
+		} else if !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("compileExpr(%q) error %q, want it to contain %q", tt.src, err, tt.err)
+		}
+	}
+}
+
+// Sonlu olmayan script bahsi bakiyeyi bozmamalı, stratejinin kendi bahsine dönülmeli
+func TestScriptStrategyRejectsNonFiniteBet(t *testing.T) {
+	tests := []struct {
+		bet  string
+		want float64
+	}{
+		{"unit * 2", 20},
+		{"unit - 100", 0},
+		{"bankroll * bankroll", 10},
+		{"-bankroll * bankroll", 10},
+		{"bankroll * bankroll - bankroll * bankroll", 10},
+	}
+	for _, tt := range tests {
+		cs := &CountingStrategy{Name: "script", BaseStrategy: &DynamicStrategy{}, Deck: NewDeck(1, nil)}
+		s, err := NewScriptStrategy(cs, &ScriptConfig{Bet: tt.bet})
+		if err != nil {
+			t.Fatalf("%s: %v", tt.bet, err)
+		}
+		if got := s.GetBetUnit(10, 1e300); got != tt.want {
+			t.Errorf("bet %q = %v, want %v", tt.bet, got, tt.want)
+		}
+	}
+}
+
+// Ortam bir kez kurulduğu için her karar base() ve legal() için kendi durumunu görmelidir
+func TestScriptStrategyReusesEnv(t *testing.T) {
+	cs := &CountingStrategy{Name: "script", BaseStrategy: &DynamicStrategy{Fallback: "stand", Actions: map[string][]string{
+		"hard_11_vs_6":  {"double", "hit"},
+		"hard_16_vs_10": {"hit"},
+	}}}
+	s, err := NewScriptStrategy(cs, &ScriptConfig{Action: `legal("double") ? base() : "stand"`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		ranks     []string
+		up        string
+		canDouble bool
+		want      string
+	}{
+		{[]string{"5", "6"}, "6", true, "double,hit"},
+		{[]string{"10", "6"}, "K", true, "hit"},
+		{[]string{"5", "6"}, "6", false, "stand"},
+		{[]string{"5", "6"}, "6", true, "double,hit"},
+	}
+	for _, tt := range tests {
+		ctx := &DecisionContext{Hand: testHand(tt.ranks...), DealerUp: Card{Rank: tt.up}, CanDouble: tt.canDouble}
+		actions, _, _, _ := s.GetAction(ctx)
+		if got := strings.Join(actions, ","); got != tt.want {
+			t.Errorf("%v vs %s (can double %v) = %q, want %q", tt.ranks, tt.up, tt.canDouble, got, tt.want)
+		}
+	}
+
+	bet, err := NewScriptStrategy(cs, &ScriptConfig{Bet: "max(base(), unit * 2)"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if allocs := testing.AllocsPerRun(100, func() { bet.GetBetUnit(10, 1000) }); allocs > 0 {
+		t.Errorf("scripted GetBetUnit allocates %v times per call", allocs)
+	}
+}
@@ -5,6 +5,9 @@ import (
 	"strings"
 )
 
+// Dealer blackjack yaptığında oyuncu karar vermeden kaydedilen iz anahtarı
+const NoDecisionKey = "No Decision"
+
 // DecisionLogEntry, bir el için alınan stratejik kararın tüm adımlarını kaydeder.
 type DecisionLogEntry struct {
 	Key          string   `json:"key"`
@@ -12,6 +15,8 @@ type DecisionLogEntry struct {
 	FinalAction  string   `json:"final_action"`
 	IsDeviation  bool     `json:"is_deviation"`
 	IsFallback   bool     `json:"is_fallback"`
+	HandID       string   `json:"-"` // kararı veren elin ID'si (split çocuklarına kopyalandığında değişmez)
+	Legal        []string `json:"-"` // karar anında kurallara göre uygulanabilen aksiyonlar
 }
 
 type Hand struct {
@@ -19,6 +24,7 @@ type Hand struct {
 	BoxID         string             `json:"box_id"`
 	Cards         []Card             `json:"cards"`
 	BetAmount     float64            `json:"bet_amount"`
+	InitialBet    float64            `json:"-"` // double öncesi ana bahis, EV'yi birim bahse çevirmek için
 	Payout        float64            `json:"payout"`
 	Result        string             `json:"result"`
 	IsSplitChild  bool               `json:"is_split_child"`
@@ -26,6 +32,8 @@ type Hand struct {
 	DecisionTrace []DecisionLogEntry `json:"decision_trace"`
 	FinalAction   string             `json:"-"` // Bu loglama için geçici bir alandır
 	StrategyActions []string         `json:"-"` // Bu loglama için geçici bir alandır
+	LegalActions    []string         `json:"-"` // Bu loglama için geçici bir alandır
+	InheritedDecisions int           `json:"-"` // Split ile üst elden kopyalanan karar sayısı
 }
 
 func NewHand(bet float64, boxID string, handID int) *Hand {
@@ -34,6 +42,7 @@ func NewHand(bet float64, boxID string, handID int) *Hand {
 		BoxID:         boxID,
 		Cards:         []Card{},
 		BetAmount:     bet,
+		InitialBet:    bet,
 		DecisionTrace: []DecisionLogEntry{},
 	}
 }
@@ -44,8 +53,10 @@ func NewSplitHand(from *Hand, handID int) *Hand {
 		BoxID:         from.BoxID,
 		Cards:         []Card{},
 		BetAmount:     from.BetAmount,
+		InitialBet:    from.InitialBet,
 		IsSplitChild:  true,
 		DecisionTrace: append([]DecisionLogEntry{}, from.DecisionTrace...),
+		InheritedDecisions: len(from.DecisionTrace),
 	}
 }
 
@@ -55,8 +66,9 @@ func (h *Hand) AddCard(c Card) {
 
 // SetDecisionTrace, bir el için önerilen stratejiyi ve nihai kararı kaydeder.
 // Bu fonksiyon, executeBoxActions içinde çağrılır.
-func (h *Hand) SetDecisionTrace(actions []string) {
+func (h *Hand) SetDecisionTrace(actions, legal []string) {
 	h.StrategyActions = actions
+	h.LegalActions = legal
 }
 
 // FinalizeDecision, el için nihai kararı kaydeder.
@@ -67,6 +79,8 @@ func (h *Hand) FinalizeDecision(key, finalAction string, isDeviation, isFallback
 		FinalAction: finalAction,
 		IsDeviation: isDeviation,
 		IsFallback:  isFallback,
+		HandID:      h.ID,
+		Legal:       h.LegalActions,
 	}
 	h.DecisionTrace = append(h.DecisionTrace, logEntry)
 }
@@ -88,6 +102,21 @@ func (h *Hand) CalculateValue() int {
 	return total
 }
 
+// IsSoft, elde 11 sayılan bir as olup olmadığını döndürür (kart sayısından bağımsız; A,2,3 soft 16'dır)
+func (h *Hand) IsSoft() bool {
+	hard := 0
+	hasAce := false
+	for _, c := range h.Cards {
+		if c.Rank == "A" {
+			hasAce = true
+			hard++
+		} else {
+			hard += c.Value()
+		}
+	}
+	return hasAce && hard+10 <= 21
+}
+
 func (h *Hand) IsBlackjack() bool {
 	return len(h.Cards) == 2 && h.CalculateValue() == 21 && !h.IsSplitChild
 }
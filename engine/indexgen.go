@@ -0,0 +1,402 @@
+package engine
+
+import (
+	"math"
+	"math/rand"
+
+	"simjack/config"
+)
+
+// IndexGenOptions, sapma (index) üretimi için masa kurallarını ve simülasyon ayarlarını taşır.
+type IndexGenOptions struct {
+	NumDecks            int
+	DecksRemaining      float64 // karar anında shoe'da kalan deste sayısı
+	HitOnSoft17         bool
+	AllowDAS            bool
+	DoubleFirstTwoOnly  bool
+	AllowSurrender      bool
+	SurrenderAgainstAce bool
+	DealerTakesHoleCard bool
+	MaxSplits           int
+	MinCount            int
+	MaxCount            int
+	Trials              int // her (anahtar, true count) çifti için oynanan el sayısı
+}
+
+// Simülasyon config'inden masa kurallarını alarak varsayılan seçenekleri üretir
+func IndexGenOptionsFromConfig(cfg config.SimulationConfig) IndexGenOptions {
+	remaining := float64(cfg.NumDecks) / 2
+	if remaining < 1 {
+		remaining = 1
+	}
+	return IndexGenOptions{
+		NumDecks:            cfg.NumDecks,
+		DecksRemaining:      remaining,
+		HitOnSoft17:         cfg.HitOnSoft17,
+		AllowDAS:            cfg.AllowDoubleAfterSplit,
+		DoubleFirstTwoOnly:  cfg.DoubleFirstTwoOnly,
+		AllowSurrender:      cfg.AllowSurrender,
+		SurrenderAgainstAce: cfg.SurrenderAgainstAce,
+		DealerTakesHoleCard: cfg.DealerTakesHoleCard,
+		MaxSplits:           cfg.MaxSplits,
+		MinCount:            -6,
+		MaxCount:            10,
+		Trials:              2000,
+	}
+}
+
+// IndexEstimate, üretilen bir index'in index count'unda yeni aksiyonun temel aksiyona göre
+// birim bahis başına beklenen kazancı ve bu farkın standart hatasıdır
+type IndexEstimate struct {
+	Gain   float64 `json:"gain"`
+	StdErr float64 `json:"std_err"`
+}
+
+// GenerateDeviations, stratejideki her anahtar için en iyi aksiyonun hangi true count'ta
+// değiştiğini simülasyonla bulur ve strateji JSON'una doğrudan eklenebilecek bir deviations bloğu
+// ile her index'in tahmin hatasını döndürür.
+//
+// Engine sapmaları yalnızca TC >= AtCount yönünde uyguladığı için, sadece yüksek count'larda
+// temel aksiyondan ayrılan anahtarlar için kural üretilir. Alternatif aksiyonun en az en yüksek
+// iki count'ta kazanması gerekir.
+func GenerateDeviations(data CountingStrategyFile, opts IndexGenOptions) (map[string]DeviationRule, map[string]IndexEstimate) {
+	base := &CountingStrategy{
+		BaseStrategy: &DynamicStrategy{Fallback: data.Fallback, Actions: data.Actions},
+		Name:         "index-gen",
+	}
+	fullPool := shoePool(opts.NumDecks)
+
+	deviations := map[string]DeviationRule{}
+	estimates := map[string]IndexEstimate{}
+	for key, actions := range data.Actions {
+		k, err := ParseStrategyKey(key)
+		if err != nil {
+			continue
+		}
+		playerCards, ok := representativeCards(k)
+		if !ok {
+			continue
+		}
+		upRank := k.Dealer
+
+		candidates := candidateActions(k, opts)
+		baseAction := firstLegalAction(actions, k, opts)
+		tables := make([]*forcedRoundTable, len(candidates))
+		for i, action := range candidates {
+			tables[i] = newForcedRoundTable(action, base, opts)
+		}
+		gen := newShoeGenerator(fullPool, playerCards, upRank, opts)
+
+		best := make([]int, 0, opts.MaxCount-opts.MinCount+1)
+		results := make([]actionResults, 0, cap(best))
+		for tc := opts.MinCount; tc <= opts.MaxCount; tc++ {
+			r := estimateActionEVs(gen, tc, tables, opts.Trials)
+			bestIdx := 0
+			for i := range candidates {
+				if r.mean(i) > r.mean(bestIdx) {
+					bestIdx = i
+				}
+			}
+			best = append(best, bestIdx)
+			results = append(results, r)
+		}
+
+		top := best[len(best)-1]
+		if candidates[top] == baseAction {
+			continue
+		}
+		i := len(best) - 1
+		for i > 0 && best[i-1] == top {
+			i--
+		}
+		// Yalnızca en üst count'ta öne geçen aksiyonlar çoğunlukla simülasyon gürültüsüdür
+		if len(best) > 1 && i == len(best)-1 {
+			continue
+		}
+		deviations[key] = DeviationRule{AtCount: opts.MinCount + i, Action: candidates[top]}
+		if b := indexOf(candidates, baseAction); b >= 0 {
+			estimates[key] = IndexEstimate{Gain: results[i].mean(top) - results[i].mean(b), StdErr: results[i].diffStdErr(top, b)}
+		}
+	}
+	return deviations, estimates
+}
+
+func indexOf(list []string, v string) int {
+	for i, s := range list {
+		if s == v {
+			return i
+		}
+	}
+	return -1
+}
+
+// representativeCards, bir anahtarı temsil eden iki kartlık başlangıç elini seçer
+func representativeCards(k StrategyKey) ([]string, bool) {
+	if k.Cards > 2 || k.Namespace != "" {
+		return nil, false // index üretimi yalnızca başlangıç elleri için yapılır
+	}
+	switch k.Kind {
+	case KeyKindPair:
+		return []string{k.Player, k.Player}, true
+	case KeyKindSoft:
+		other := k.Total() - 11
+		if other < 2 || other > 10 {
+			return nil, false // soft 12 = A,A çifti, soft 21 = blackjack
+		}
+		return []string{"A", rankForValue(other)}, true
+	case KeyKindHard:
+		total := k.Total()
+		switch {
+		case total == 20:
+			return []string{"10", "J"}, true
+		case total >= 12 && total <= 19:
+			return []string{"10", rankForValue(total - 10)}, true
+		case total >= 5 && total <= 11:
+			lo := (total - 1) / 2
+			return []string{rankForValue(lo), rankForValue(total - lo)}, true
+		}
+	}
+	return nil, false
+}
+
+func rankForValue(v int) string {
+	if v == 10 {
+		return "10"
+	}
+	return Ranks[v-2]
+}
+
+// candidateActions, başlangıç elinde kurallara göre denenebilecek aksiyonları döndürür
+func candidateActions(k StrategyKey, opts IndexGenOptions) []string {
+	actions := []string{"stand", "hit", "double"}
+	if k.Kind == KeyKindPair && opts.MaxSplits > 0 {
+		actions = append(actions, "split")
+	}
+	if opts.AllowSurrender && (k.Dealer != "A" || opts.SurrenderAgainstAce) {
+		actions = append(actions, "surrender")
+	}
+	return actions
+}
+
+// firstLegalAction, executeBoxActions'taki sırayla başlangıç elinde uygulanacak ilk aksiyonu bulur
+func firstLegalAction(actions []string, k StrategyKey, opts IndexGenOptions) string {
+	legal := candidateActions(k, opts)
+	for _, a := range actions {
+		for _, l := range legal {
+			if a == l {
+				return a
+			}
+		}
+	}
+	return "stand"
+}
+
+// actionResults, bir true count'ta her aday aksiyonun deneme başına sonuçlarıdır ([aksiyon][deneme])
+type actionResults [][]float64
+
+func (r actionResults) mean(i int) float64 {
+	sum := 0.0
+	for _, v := range r[i] {
+		sum += v
+	}
+	return sum / float64(len(r[i]))
+}
+
+// diffStdErr, i ve j aksiyonlarının ortalama farkının standart hatasıdır. Aksiyonlar her denemede
+// aynı shoe ile oynandığı için fark deneme bazında (eşli) alınır.
+func (r actionResults) diffStdErr(i, j int) float64 {
+	n := len(r[i])
+	if n < 2 {
+		return 0
+	}
+	mean := r.mean(i) - r.mean(j)
+	ss := 0.0
+	for t := 0; t < n; t++ {
+		d := r[i][t] - r[j][t] - mean
+		ss += d * d
+	}
+	return math.Sqrt(ss / float64(n-1) / float64(n))
+}
+
+// estimateActionEVs, verilen true count'ta her aday aksiyonun birim bahis başına sonuçlarını ölçer.
+// Varyansı azaltmak için her denemede tüm aksiyonlar aynı karışık shoe ile oynanır.
+func estimateActionEVs(gen *shoeGenerator, tc int, tables []*forcedRoundTable, trials int) actionResults {
+	results := make(actionResults, len(tables))
+	for i := range results {
+		results[i] = make([]float64, trials)
+	}
+	for t := 0; t < trials; t++ {
+		shoe := gen.shoeAtCount(tc)
+		for i, table := range tables {
+			results[i][t] = table.play(shoe)
+		}
+	}
+	return results
+}
+
+// shoePool, NumDecks destelik karıştırılmamış kart havuzudur
+func shoePool(numDecks int) []Card {
+	pool := make([]Card, 0, numDecks*len(Suits)*len(Ranks))
+	for i := 0; i < numDecks; i++ {
+		for _, suit := range Suits {
+			for _, rank := range Ranks {
+				pool = append(pool, Card{Rank: rank, Suit: suit})
+			}
+		}
+	}
+	return pool
+}
+
+// shoeGenerator, bir anahtar için oyuncunun iki kartı ve dealer açık kartı çıkarılmış havuzu bir kez
+// hazırlar. Havuz denemeler arasında yalnızca yeniden karıştırılır; her karıştırma önceki sıradan
+// bağımsız olduğu için havuzun baştan kurulmasına gerek yoktur.
+type shoeGenerator struct {
+	p1, up, p2 Card
+	pool       []Card // kalan kartlar: görülenler, ardından görülmeyenler
+	shoe       []Card // dönen shoe: p1, up, p2, görülmeyen kartlar
+	unseen     int    // karar anında shoe'da kalan kart sayısı
+}
+
+func newShoeGenerator(fullPool []Card, playerRanks []string, upRank string, opts IndexGenOptions) *shoeGenerator {
+	pool := append([]Card{}, fullPool...)
+	take := func(rank string) Card {
+		for i, c := range pool {
+			if c.Rank == rank {
+				pool = append(pool[:i], pool[i+1:]...)
+				return c
+			}
+		}
+		return Card{Rank: rank, Suit: Suits[0]}
+	}
+	g := &shoeGenerator{}
+	g.p1 = take(playerRanks[0])
+	g.up = take(upRank)
+	g.p2 = take(playerRanks[1])
+	g.pool = pool
+
+	g.unseen = int(math.Round(opts.DecksRemaining * 52))
+	if g.unseen > len(pool) {
+		g.unseen = len(pool)
+	}
+	if g.unseen < 20 {
+		g.unseen = 20
+	}
+	g.shoe = make([]Card, 0, g.unseen+3)
+	return g
+}
+
+// shoeAtCount, oyuncunun iki kartı ve dealer açık kartı görüldükten sonra running count'u
+// hedef true count'a denk gelen bir shoe üretir. Dönen dizi dağıtım sırasındadır: p1, up, p2, kalanlar.
+// Dizi bir sonraki çağrıda yeniden kullanılır.
+func (g *shoeGenerator) shoeAtCount(tc int) []Card {
+	// Görülmeyen kartlar havuzun sonuna rastgele seçilir (kısmi Fisher-Yates); görülen kartların
+	// sırası önemsizdir
+	pool := g.pool
+	for i := len(pool) - 1; i >= len(pool)-g.unseen; i-- {
+		j := rand.Intn(i + 1)
+		pool[i], pool[j] = pool[j], pool[i]
+	}
+
+	seen := pool[:len(pool)-g.unseen]
+	unseen := pool[len(pool)-g.unseen:]
+
+	targetRC := int(math.Round(float64(tc) * float64(g.unseen) / 52.0))
+	rc := getHiLoValue(g.p1) + getHiLoValue(g.up) + getHiLoValue(g.p2)
+	for _, c := range seen {
+		rc += getHiLoValue(c)
+	}
+
+	// Görülen ve görülmeyen kartlar arasında rastgele takas yaparak running count'u hedefe taşı.
+	// Hedef bu shoe boyutunda ulaşılamazsa en yakın değerle devam edilir.
+	for attempts := 0; rc != targetRC && attempts < 20000 && len(seen) > 0; attempts++ {
+		diff := targetRC - rc
+		si := rand.Intn(len(seen))
+		ui := rand.Intn(len(unseen))
+		delta := getHiLoValue(unseen[ui]) - getHiLoValue(seen[si])
+		if delta != 0 && (delta > 0) == (diff > 0) && abs(delta) <= abs(diff) {
+			seen[si], unseen[ui] = unseen[ui], seen[si]
+			rc += delta
+		}
+	}
+
+	g.shoe = append(g.shoe[:0], g.p1, g.up, g.p2)
+	g.shoe = append(g.shoe, unseen...)
+	return g.shoe[:len(g.shoe):len(g.shoe)]
+}
+
+// forcedRoundTable, bir aksiyon için denemeler boyunca yeniden kullanılan tek oyunculu masadır
+type forcedRoundTable struct {
+	engine   *Engine
+	player   *Player
+	strategy *forcedActionStrategy
+	numDecks int
+}
+
+func newForcedRoundTable(action string, base Strategy, opts IndexGenOptions) *forcedRoundTable {
+	forced := &forcedActionStrategy{base: base, action: action}
+	p := NewPlayer(config.PlayerConfig{PlayerID: 1, Owner: "index-gen", InitialBalance: 1e9}, forced)
+	box := NewBoxWithConfig(config.BoxAssignment{Index: 1, MainBet: 1}, p)
+	p.Boxes = []*Box{box}
+	boxes := make([]*Box, 7)
+	boxes[0] = box
+
+	e := &Engine{
+		Dealer:              NewDealer(),
+		Boxes:               boxes,
+		Players:             []*Player{p},
+		RoundCount:          1,
+		CurrentRound:        1,
+		CurrentShoeNumber:   1,
+		HitOnSoft17:         opts.HitOnSoft17,
+		AllowDAS:            opts.AllowDAS,
+		DoubleFirstTwoOnly:  opts.DoubleFirstTwoOnly,
+		AllowSurrender:      opts.AllowSurrender,
+		SurrenderAgainstAce: opts.SurrenderAgainstAce,
+		DealerTakesHoleCard: opts.DealerTakesHoleCard,
+		MaxSplits:           opts.MaxSplits,
+		MinBet:              1,
+		MaxBet:              1,
+	}
+	return &forcedRoundTable{engine: e, player: p, strategy: forced, numDecks: opts.NumDecks}
+}
+
+// play, ilk kararı masanın aksiyonuyla zorlayıp geri kalan eli temel stratejiyle oynatır ve
+// birim bahis başına net sonucu döndürür. Deck kartları yalnızca baştan tükettiği için aynı shoe
+// tüm aksiyonlar arasında kopyalanmadan paylaşılır.
+func (t *forcedRoundTable) play(shoe []Card) float64 {
+	t.engine.Deck = &Deck{Cards: shoe, NumDecks: t.numDecks}
+	t.strategy.used = false
+	start := t.player.Balance
+	t.engine.PlayRound()
+	return t.player.Balance - start
+}
+
+// forcedActionStrategy, ilk kararda verilen aksiyonu döndürür, sonraki kararları temel stratejiye bırakır
+type forcedActionStrategy struct {
+	base   Strategy
+	action string
+	used   bool
+}
+
+func (s *forcedActionStrategy) GetAction(ctx *DecisionContext) ([]string, bool, bool, string) {
+	if !s.used {
+		s.used = true
+		return []string{s.action}, false, false, "forced"
+	}
+	return s.base.GetAction(ctx)
+}
+
+func (s *forcedActionStrategy) DecideInsurance() bool {
+	return false
+}
+
+func (s *forcedActionStrategy) String() string {
+	return "forced:" + s.action
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,67 @@
+package engine
+
+import (
+	"math"
+	"testing"
+)
+
+func TestActionResultsStdErr(t *testing.T) {
+	r := actionResults{
+		{1, -1, 1, -1},
+		{0, -1, 0, -1},
+		{1, -1, 1, -1},
+	}
+	if got := r.mean(0); got != 0 {
+		t.Errorf("mean(0) = %v, want 0", got)
+	}
+	// Eşli farklar 1, 0, 1, 0: ortalama 0.5, örnek varyansı 1/3
+	if got, want := r.diffStdErr(0, 1), math.Sqrt(1.0/3/4); math.Abs(got-want) > 1e-12 {
+		t.Errorf("diffStdErr(0, 1) = %v, want %v", got, want)
+	}
+	if got := r.diffStdErr(0, 2); got != 0 {
+		t.Errorf("diffStdErr of identical results = %v, want 0", got)
+	}
+}
+
+func TestShoeGeneratorHitsTargetCount(t *testing.T) {
+	opts := IndexGenOptions{NumDecks: 6, DecksRemaining: 3}
+	gen := newShoeGenerator(shoePool(opts.NumDecks), []string{"10", "6"}, "10", opts)
+	for _, tc := range []int{-4, 0, 3, 8} {
+		for trial := 0; trial < 20; trial++ {
+			shoe := gen.shoeAtCount(tc)
+			if len(shoe) != 3+156 || shoe[0].Rank != "10" || shoe[1].Rank != "10" || shoe[2].Rank != "6" {
+				t.Fatalf("tc %d: shoe starts %v with %d cards", tc, shoe[:3], len(shoe))
+			}
+			// Görülmeyen kartların Hi-Lo toplamı, görülen kartların running count'unun tersidir
+			rc := 0
+			for _, c := range shoe[3:] {
+				rc -= getHiLoValue(c)
+			}
+			if want := tc * 3; rc != want {
+				t.Errorf("tc %d: running count %d, want %d", tc, rc, want)
+			}
+		}
+	}
+}
+
+// Aynı masa ve aynı shoe tekrar oynandığında sonuç değişmemeli; shoe masalar arasında paylaşılır
+func TestForcedRoundTableReuse(t *testing.T) {
+	opts := IndexGenOptions{NumDecks: 6, DecksRemaining: 3, MaxSplits: 3, DealerTakesHoleCard: true}
+	base := &CountingStrategy{BaseStrategy: &DynamicStrategy{Fallback: "stand"}}
+	gen := newShoeGenerator(shoePool(opts.NumDecks), []string{"10", "6"}, "10", opts)
+	hit := newForcedRoundTable("hit", base, opts)
+	stand := newForcedRoundTable("stand", base, opts)
+	for trial := 0; trial < 50; trial++ {
+		shoe := gen.shoeAtCount(0)
+		first := append([]Card{}, shoe...)
+		h1, s1 := hit.play(shoe), stand.play(shoe)
+		if h1 != hit.play(shoe) || s1 != stand.play(shoe) {
+			t.Fatalf("trial %d: replaying the same shoe changed the result", trial)
+		}
+		for i := range first {
+			if shoe[i] != first[i] {
+				t.Fatalf("trial %d: playing a round modified the shared shoe", trial)
+			}
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package engine
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Strateji kalıtımı: bir strateji dosyası "extends" ile başka bir stratejiyi temel alır ve
+// yalnızca farklı olan alanları yazar. "overlays" ile kural setine özel düzeltmeler (ör. 6:5
+// ayarları) herhangi bir temelin üstüne eklenebilir. Birleştirme sırası: extends zinciri,
+// overlays (sırayla), dosyanın kendi alanları. actions, deviations ve hole_card_actions anahtar
+// bazında, bet_ramp min_count bazında birleşir; diğer alanlar tamamen değiştirilir.
+
+// UnmarshalJSON, dosyada hangi alanların yazıldığını kaydeder; böylece birleştirmede
+// açıkça false/0 verilen alanlar yazılmamış alanlardan ayrılabilir.
+func (f *CountingStrategyFile) UnmarshalJSON(raw []byte) error {
+	type plain CountingStrategyFile
+	if err := json.Unmarshal(raw, (*plain)(f)); err != nil {
+		return err
+	}
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return err
+	}
+	f.present = map[string]bool{}
+	for name := range fields {
+		f.present[name] = true
+	}
+	return nil
+}
+
+// has, alanın dosyada yazılıp yazılmadığını döndürür. Kodda oluşturulan dosyalarda
+// (JSON'dan okunmamış) sıfır olmayan alanlar yazılmış sayılır.
+func (f *CountingStrategyFile) has(name string, v reflect.Value) bool {
+	if f.present == nil {
+		return !v.IsZero()
+	}
+	return f.present[name]
+}
+
+// StrategySource, ada göre ham (extends/overlays çözülmemiş) strateji dosyasını döndürür
+type StrategySource func(name string) (CountingStrategyFile, error)
+
+// StrategyBundleSource, yalnızca verilen stratejilerden okuyan kaynaktır (ör. stdin ile gelenler);
+// üst stratejiler ve overlay'ler diskten okunmaz.
+func StrategyBundleSource(bundle map[string]CountingStrategyFile) StrategySource {
+	return func(name string) (CountingStrategyFile, error) {
+		data, ok := bundle[name]
+		if !ok {
+			return data, fmt.Errorf("strategy %s not found in supplied strategies", name)
+		}
+		return data, nil
+	}
+}
+
+// ResolveStrategyFile, extends zincirini ve overlay'leri strateji dizinindeki dosyalardan
+// çözerek tek bir strateji dosyasına birleştirir. Döngüsel kalıtım hata döndürür.
+func ResolveStrategyFile(name string, data CountingStrategyFile) (CountingStrategyFile, error) {
+	return ResolveStrategyFileFrom(name, data, readStrategyFile)
+}
+
+// ResolveStrategyFileFrom, ResolveStrategyFile gibidir ama üst stratejileri ve overlay'leri source'tan okur
+func ResolveStrategyFileFrom(name string, data CountingStrategyFile, source StrategySource) (CountingStrategyFile, error) {
+	return resolveStrategyFile(name, data, source, nil)
+}
+
+func resolveStrategyFile(name string, data CountingStrategyFile, source StrategySource, chain []string) (CountingStrategyFile, error) {
+	if data.Extends == "" && len(data.Overlays) == 0 {
+		return data, nil
+	}
+	for _, n := range chain {
+		if n == name {
+			return data, &inheritanceCycleError{append(chain, name)}
+		}
+	}
+	chain = append(append([]string{}, chain...), name)
+
+	resolved := CountingStrategyFile{}
+	if data.Extends != "" {
+		parent, err := readResolvedStrategyFile(data.Extends, source, chain)
+		if err != nil {
+			return data, wrapInheritanceError(err, "strategy %s extends %s", name, data.Extends)
+		}
+		resolved = parent
+	}
+	for _, overlay := range data.Overlays {
+		o, err := readResolvedStrategyFile(overlay, source, chain)
+		if err != nil {
+			return data, wrapInheritanceError(err, "strategy %s overlay %s", name, overlay)
+		}
+		resolved = mergeStrategyFiles(resolved, o)
+	}
+	resolved = mergeStrategyFiles(resolved, data)
+	resolved.Extends, resolved.Overlays = "", nil
+	return resolved, nil
+}
+
+// inheritanceCycleError, extends/overlays zincirinde bir stratejinin kendine geri döndüğünü bildirir
+type inheritanceCycleError struct {
+	chain []string
+}
+
+func (e *inheritanceCycleError) Error() string {
+	return fmt.Sprintf("strategy inheritance cycle: %s", strings.Join(e.chain, " -> "))
+}
+
+// wrapInheritanceError, hatayı zincirdeki adımla açıklar; döngü hatası zaten tüm zinciri içerdiği için olduğu gibi döner
+func wrapInheritanceError(err error, format string, args ...interface{}) error {
+	var cycle *inheritanceCycleError
+	if errors.As(err, &cycle) {
+		return err
+	}
+	return fmt.Errorf("%s: %w", fmt.Sprintf(format, args...), err)
+}
+
+func readResolvedStrategyFile(name string, source StrategySource, chain []string) (CountingStrategyFile, error) {
+	data, err := source(name)
+	if err != nil {
+		return data, err
+	}
+	return resolveStrategyFile(name, data, source, chain)
+}
+
+// mergeStrategyFiles, over'da yazılmış alanları base'in üzerine yazar
+func mergeStrategyFiles(base, over CountingStrategyFile) CountingStrategyFile {
+	merged := base
+	mv := reflect.ValueOf(&merged).Elem()
+	bv, ov := reflect.ValueOf(base), reflect.ValueOf(over)
+	t := ov.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name == "" || name == "-" || !over.has(name, ov.Field(i)) {
+			continue
+		}
+		switch {
+		case name == "bet_ramp":
+			merged.BetRamp = mergeBetRamp(base.BetRamp, over.BetRamp)
+		case t.Field(i).Type.Kind() == reflect.Map && !bv.Field(i).IsNil():
+			m := reflect.MakeMap(t.Field(i).Type)
+			for _, src := range []reflect.Value{bv.Field(i), ov.Field(i)} {
+				iter := src.MapRange()
+				for iter.Next() {
+					m.SetMapIndex(iter.Key(), iter.Value())
+				}
+			}
+			mv.Field(i).Set(m)
+		default:
+			mv.Field(i).Set(ov.Field(i))
+		}
+	}
+
+	if base.present != nil || over.present != nil {
+		merged.present = map[string]bool{}
+		for _, p := range []map[string]bool{base.present, over.present} {
+			for name := range p {
+				merged.present[name] = true
+			}
+		}
+	}
+	return merged
+}
+
+// mergeBetRamp, aynı min_count'a sahip kademeleri değiştirir, yenilerini ekler
+func mergeBetRamp(base, over []BetRampTier) []BetRampTier {
+	byCount := map[int]BetRampTier{}
+	for _, tiers := range [][]BetRampTier{base, over} {
+		for _, tier := range tiers {
+			byCount[tier.MinCount] = tier
+		}
+	}
+	merged := make([]BetRampTier, 0, len(byCount))
+	for _, tier := range byCount {
+		merged = append(merged, tier)
+	}
+	sort.Slice(merged, func(i, j int) bool { return merged[i].MinCount < merged[j].MinCount })
+	return merged
+}
@@ -0,0 +1,110 @@
+package engine
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testBundle, JSON metinlerinden stdin'den gelmiş gibi bir strateji kümesi oluşturur
+func testBundle(t *testing.T, files map[string]string) map[string]CountingStrategyFile {
+	t.Helper()
+	bundle := map[string]CountingStrategyFile{}
+	for name, raw := range files {
+		var data CountingStrategyFile
+		if err := json.Unmarshal([]byte(raw), &data); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		bundle[name] = data
+	}
+	return bundle
+}
+
+func TestResolveStrategyFileMerge(t *testing.T) {
+	bundle := testBundle(t, map[string]string{
+		"base": `{
+			"fallback": "hit",
+			"counting_enabled": true,
+			"actions": {"hard_16_vs_10": ["hit"], "hard_12_vs_2": ["hit"]},
+			"deviations": {"hard_16_vs_10": {"at_count": 0, "action": "stand"}},
+			"bet_ramp": [{"min_count": 1, "bet_unit": 2}, {"min_count": 3, "bet_unit": 4}]
+		}`,
+		"six-five": `{"actions": {"hard_12_vs_2": ["stand"]}, "bet_ramp": [{"min_count": 3, "bet_unit": 3}]}`,
+		"child": `{
+			"extends": "base",
+			"overlays": ["six-five"],
+			"counting_enabled": false,
+			"actions": {"hard_16_vs_10": ["surrender", "hit"]},
+			"bet_ramp": [{"min_count": 5, "bet_unit": 8}]
+		}`,
+	})
+
+	got, err := ResolveStrategyFileFrom("child", bundle["child"], StrategyBundleSource(bundle))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Extends != "" || got.Overlays != nil {
+		t.Errorf("resolved file still has extends %q / overlays %v", got.Extends, got.Overlays)
+	}
+	if got.Fallback != "hit" {
+		t.Errorf("fallback = %q, want inherited %q", got.Fallback, "hit")
+	}
+	if got.CountingEnabled {
+		t.Error("counting_enabled: explicit false in child should override inherited true")
+	}
+	wantActions := map[string][]string{"hard_16_vs_10": {"surrender", "hit"}, "hard_12_vs_2": {"stand"}}
+	if !reflect.DeepEqual(got.Actions, wantActions) {
+		t.Errorf("actions = %v, want %v", got.Actions, wantActions)
+	}
+	if _, ok := got.Deviations["hard_16_vs_10"]; !ok {
+		t.Error("deviation from base was lost")
+	}
+	wantRamp := []BetRampTier{{1, 2}, {3, 3}, {5, 8}}
+	if !reflect.DeepEqual(got.BetRamp, wantRamp) {
+		t.Errorf("bet_ramp = %v, want %v", got.BetRamp, wantRamp)
+	}
+}
+
+func TestResolveStrategyFileErrors(t *testing.T) {
+	bundle := testBundle(t, map[string]string{
+		"a":      `{"extends": "b"}`,
+		"b":      `{"extends": "a"}`,
+		"self":   `{"overlays": ["self"]}`,
+		"orphan": `{"extends": "missing"}`,
+	})
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"a", "strategy inheritance cycle: a -> b -> a"},
+		{"self", "strategy inheritance cycle: self -> self"},
+		{"orphan", "strategy orphan extends missing: strategy missing not found in supplied strategies"},
+	}
+	for _, tt := range tests {
+		_, err := ResolveStrategyFileFrom(tt.name, bundle[tt.name], StrategyBundleSource(bundle))
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("%s: error %v, want %q", tt.name, err, tt.want)
+		}
+	}
+}
+
+// Kalıtımı olmayan dosya kaynağa hiç bakılmadan aynen döner
+func TestResolveStrategyFileWithoutInheritance(t *testing.T) {
+	data := CountingStrategyFile{Fallback: "stand"}
+	source := func(name string) (CountingStrategyFile, error) {
+		t.Fatalf("source called for %s", name)
+		return CountingStrategyFile{}, nil
+	}
+	got, err := ResolveStrategyFileFrom("plain", data, source)
+	if err != nil || got.Fallback != "stand" {
+		t.Errorf("got %+v, %v", got, err)
+	}
+}
+
+func TestLoadCountingStrategyFromDataRequiresResolvedData(t *testing.T) {
+	_, err := LoadCountingStrategyFromData("child", CountingStrategyFile{Extends: "base"})
+	if err == nil || !strings.Contains(err.Error(), "must be resolved") {
+		t.Errorf("error %v, want unresolved data to be rejected", err)
+	}
+}
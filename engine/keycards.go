@@ -0,0 +1,209 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+)
+
+// KeyCardConfig, strateji dosyasındaki "key_cards" bölümüdür (ace sequencing / key-card tracking).
+// Yeni shoe karıştırılırken önceki shoe'nun discard'larında her hedef kartın (ör. as) hemen önündeki
+// SequenceLength kart ezberlenir. Yeni shoe'da bu dizi tekrar görülürse hedefin sonraki Window kart
+// içinde geleceği tahmin edilir; bu ancak kartların komşuluğunu koruyan karıştırmalarda işe yarar.
+type KeyCardConfig struct {
+	Targets        []string `json:"targets"`         // tahmin edilen ranklar (varsayılan ["A"]; "10" tüm onluklar)
+	SequenceLength int      `json:"sequence_length"` // hedeften önce ezberlenen kart sayısı (varsayılan 1)
+	Window         int      `json:"window"`          // anahtar diziden sonra hedefin beklendiği kart sayısı (varsayılan 2)
+	BetMultiplier  float64  `json:"bet_multiplier"`  // box'ın ilk kartı için as tahmini varsa bahis çarpanı
+	InsureOnTen    bool     `json:"insure_on_ten"`   // dealer'ın hole card'ı için onluk tahmini varsa sigorta alınır
+}
+
+func (c *KeyCardConfig) targets() []string {
+	if len(c.Targets) == 0 {
+		return []string{"A"}
+	}
+	return c.Targets
+}
+
+func (c *KeyCardConfig) sequenceLength() int {
+	if c.SequenceLength <= 0 {
+		return 1
+	}
+	return c.SequenceLength
+}
+
+func (c *KeyCardConfig) window() int {
+	if c.Window <= 0 {
+		return 2
+	}
+	return c.Window
+}
+
+// keyCardPrediction: Rank'ın shoe pozisyonu [From, To] aralığında gelmesi beklenir
+type keyCardPrediction struct {
+	Rank     string
+	From, To int
+}
+
+// keyCardTracker, anahtar dizileri ve aktif tahminleri tutar; tahminlerin tutup tutmadığını
+// round bazında kaydeder.
+type keyCardTracker struct {
+	config      *KeyCardConfig
+	deck        *Deck
+	keys        map[string][]string // anahtar dizi -> ardından gelen hedef ranklar
+	recent      []Card              // son dağıtılan SequenceLength kart
+	active      []keyCardPrediction
+	lastCovered []string // son dağıtılan kart için aktif olan tahminlerin rankları
+	outcomes    []string // bu round'da sonuçlanan tahminler (ör. "A:hit")
+	Hits        int
+	Misses      int
+}
+
+func newKeyCardTracker(cfg *KeyCardConfig, deck *Deck) *keyCardTracker {
+	return &keyCardTracker{config: cfg, deck: deck, keys: map[string][]string{}}
+}
+
+func (t *keyCardTracker) isTarget(rank string) bool {
+	for _, target := range t.config.targets() {
+		if target == rank {
+			return true
+		}
+	}
+	return false
+}
+
+func sequenceKey(cards []Card) string {
+	parts := make([]string, len(cards))
+	for i, c := range cards {
+		parts[i] = c.String()
+	}
+	return strings.Join(parts, "|")
+}
+
+// OnNewShoe, önceki shoe'nun discard'larından anahtar dizileri çıkarır. Tahminler kartların
+// komşuluğuna dayandığı için yeni konumlar kullanılmaz.
+func (t *keyCardTracker) OnNewShoe(previousDiscards []Card, newPositions []int) {
+	t.keys = map[string][]string{}
+	n := t.config.sequenceLength()
+	for i := n; i < len(previousDiscards); i++ {
+		rank := sideCountRank(previousDiscards[i].Rank)
+		if !t.isTarget(rank) {
+			continue
+		}
+		key := sequenceKey(previousDiscards[i-n : i])
+		t.keys[key] = append(t.keys[key], rank)
+	}
+}
+
+func (t *keyCardTracker) OnShuffle() {
+	t.recent = nil
+	t.active = nil
+	t.lastCovered = nil
+}
+
+func (t *keyCardTracker) OnCardsReturned(cards []Card) {}
+
+func (t *keyCardTracker) OnCardDealt(c Card) {
+	if t.deck.DrawnThisRound == 1 {
+		t.outcomes = nil
+	}
+	pos := t.deck.DrawnThisShoe - 1
+	rank := sideCountRank(c.Rank)
+
+	// Bu kart için aktif tahminleri sonuçlandır
+	t.lastCovered = nil
+	remaining := t.active[:0]
+	for _, p := range t.active {
+		covered := pos >= p.From && pos <= p.To
+		if covered {
+			t.lastCovered = append(t.lastCovered, p.Rank)
+		}
+		switch {
+		case covered && p.Rank == rank:
+			t.Hits++
+			t.outcomes = append(t.outcomes, p.Rank+":hit")
+		case pos >= p.To:
+			t.Misses++
+			t.outcomes = append(t.outcomes, p.Rank+":miss")
+		default:
+			remaining = append(remaining, p)
+		}
+	}
+	t.active = remaining
+
+	// Anahtar dizi tamamlandıysa yeni tahmin aç
+	n := t.config.sequenceLength()
+	t.recent = append(t.recent, c)
+	if len(t.recent) > n {
+		t.recent = t.recent[len(t.recent)-n:]
+	}
+	if len(t.recent) == n {
+		for _, target := range t.keys[sequenceKey(t.recent)] {
+			t.active = append(t.active, keyCardPrediction{Rank: target, From: pos + 1, To: pos + t.config.window()})
+		}
+	}
+}
+
+// predictsAt, shoe'nun pos pozisyonundaki kart için rank tahmini olup olmadığını döndürür
+func (t *keyCardTracker) predictsAt(pos int, rank string) bool {
+	for _, p := range t.active {
+		if p.Rank == rank && pos >= p.From && pos <= p.To {
+			return true
+		}
+	}
+	return false
+}
+
+// predictedLast, son dağıtılan kart için rank tahmini olup olmadığını döndürür
+func (t *keyCardTracker) predictedLast(rank string) bool {
+	for _, r := range t.lastCovered {
+		if r == rank {
+			return true
+		}
+	}
+	return false
+}
+
+// keyCardBetMultiplier, bahsi hesaplanan box'ın ilk kartı için as tahmini varsa key card bahis
+// çarpanını döndürür. Box'ın ilk kartı, round'da kendisinden önce kart alacak box'lar kadar
+// (dealOffset) ileridedir.
+func (s *CountingStrategy) keyCardBetMultiplier() float64 {
+	if s.keyTracker == nil || s.KeyCards.BetMultiplier <= 0 || !s.keyTracker.predictsAt(s.Deck.DrawnThisShoe+s.dealOffset, "A") {
+		return 1
+	}
+	return s.KeyCards.BetMultiplier
+}
+
+// KeyCardState, log için bu round'da sonuçlanan tahminleri döndürür (ör. "A:hit;A:miss")
+func (s *CountingStrategy) KeyCardState() string {
+	if s.keyTracker == nil {
+		return ""
+	}
+	return strings.Join(s.keyTracker.outcomes, ";")
+}
+
+func validateKeyCards(c *KeyCardConfig) []ValidationIssue {
+	issues := []ValidationIssue{}
+	if c == nil {
+		return issues
+	}
+	add := func(severity, format string, args ...interface{}) {
+		issues = append(issues, ValidationIssue{Severity: severity, Section: "key_cards", Message: fmt.Sprintf(format, args...)})
+	}
+	hasTen := false
+	for _, target := range c.Targets {
+		if !isRank(target) || sideCountRank(target) != target {
+			add(SeverityError, "unknown target rank %q (use \"10\" for ten-valued cards)", target)
+		}
+		hasTen = hasTen || target == "10"
+	}
+	if c.SequenceLength < 0 || c.Window < 0 {
+		add(SeverityError, "sequence_length and window must not be negative")
+	}
+	if c.BetMultiplier < 0 {
+		add(SeverityError, "bet_multiplier must not be negative")
+	}
+	if c.InsureOnTen && !hasTen {
+		add(SeverityWarning, "insure_on_ten is set but \"10\" is not a target")
+	}
+	return issues
+}
@@ -0,0 +1,44 @@
+package engine
+
+import "testing"
+
+func testKeyCardStrategy() *CountingStrategy {
+	deck := NewDeck(1, nil)
+	deck.DrawnThisShoe = 10
+	cfg := &KeyCardConfig{Targets: []string{"A", "10"}, BetMultiplier: 3, InsureOnTen: true}
+	tracker := newKeyCardTracker(cfg, deck)
+	tracker.active = []keyCardPrediction{{Rank: "A", From: 12, To: 12}}
+	tracker.lastCovered = []string{"10"}
+	return &CountingStrategy{KeyCards: cfg, Deck: deck, keyTracker: tracker}
+}
+
+// As tahmini, round'un ilk kartı için değil bahsi hesaplanan box'ın ilk kartı için kullanılır
+func TestKeyCardBetMultiplierUsesDealOffset(t *testing.T) {
+	tests := []struct {
+		offset int
+		want   float64
+	}{
+		{0, 1},
+		{1, 1},
+		{2, 3},
+		{3, 1},
+	}
+	for _, tt := range tests {
+		s := testKeyCardStrategy()
+		s.dealOffset = tt.offset
+		if got := s.keyCardBetMultiplier(); got != tt.want {
+			t.Errorf("offset %d: multiplier %v, want %v", tt.offset, got, tt.want)
+		}
+	}
+}
+
+// Hole card alınmayan oyunlarda son dağıtılan kart dealer'ın değildir
+func TestKeyCardInsuranceNeedsHoleCard(t *testing.T) {
+	for _, holeCardDealt := range []bool{true, false} {
+		s := testKeyCardStrategy()
+		s.holeCardDealt = holeCardDealt
+		if got := s.DecideInsurance(); got != holeCardDealt {
+			t.Errorf("holeCardDealt %v: DecideInsurance() = %v", holeCardDealt, got)
+		}
+	}
+}
@@ -0,0 +1,243 @@
+package engine
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Strateji anahtarlarındaki el türleri
+const (
+	KeyKindPair = "pair"
+	KeyKindSoft = "soft"
+	KeyKindHard = "hard"
+)
+
+// Dealer açık kartı için kullanılabilecek anahtar değerleri (J/Q/K -> "10")
+var DealerKeys = []string{"2", "3", "4", "5", "6", "7", "8", "9", "10", "A"}
+
+// Split sonrası anahtar ad alanları. Split'ten gelen eller önce bu ön ekli anahtarlarla aranır
+// (ör. "after_split_hard_11_vs_6"); böylece tek bir strateji dosyası DAS kapalıyken ya da
+// MaxSplits'e ulaşıldığında farklı oynanabilir.
+const (
+	KeyNamespaceAfterSplit          = "after_split"            // split'ten gelen her el
+	KeyNamespaceAfterSplitNoDAS     = "after_split_no_das"     // split sonrası double kurallarca yasakken
+	KeyNamespaceAfterSplitNoResplit = "after_split_no_resplit" // çift MaxSplits yüzünden tekrar bölünemezken
+)
+
+// KeyNamespaces, desteklenen ad alanlarıdır (tablolarda bu sırayla gösterilir)
+var KeyNamespaces = []string{KeyNamespaceAfterSplit, KeyNamespaceAfterSplitNoDAS, KeyNamespaceAfterSplitNoResplit}
+
+// splitNamespaceOrder, ad alanlarının aranma sırasıdır (en özelden genele). Çalışma anında
+// (splitNamespaces) ve strateji karşılaştırmada (FallbackKeys) aynı sıra kullanılır.
+var splitNamespaceOrder = []string{KeyNamespaceAfterSplitNoResplit, KeyNamespaceAfterSplitNoDAS, KeyNamespaceAfterSplit}
+
+// StrategyKey, "pair_8_vs_10", "soft_18_vs_9", "hard_16_vs_10" gibi bir anahtarın parçalarıdır.
+// Soft/hard anahtarlar isteğe bağlı olarak elin kart sayısını da içerebilir ("hard_16_3cards_vs_10");
+// her anahtar bir ad alanı ön eki alabilir ("after_split_pair_8_vs_10").
+type StrategyKey struct {
+	Namespace string // KeyNamespaces'ten biri, normal anahtarlarda boş
+	Kind      string // pair, soft, hard
+	Player    string // pair için kart rank'ı, soft/hard için toplam
+	Cards     int    // kart sayısına özel anahtarlarda elin kart sayısı, diğerlerinde 0
+	Dealer    string // getDealerRankKey çıktısı
+}
+
+func (k StrategyKey) String() string {
+	key := fmt.Sprintf("%s_%s_vs_%s", k.Kind, k.Player, k.Dealer)
+	if k.Cards > 0 {
+		key = fmt.Sprintf("%s_%s_%dcards_vs_%s", k.Kind, k.Player, k.Cards, k.Dealer)
+	}
+	if k.Namespace != "" {
+		key = k.Namespace + "_" + key
+	}
+	return key
+}
+
+// Base, anahtarın ad alanı ve kart sayısı olmadan genel halini döndürür
+func (k StrategyKey) Base() StrategyKey {
+	k.Namespace, k.Cards = "", 0
+	return k
+}
+
+// FallbackKeys, anahtarı ve strateji dosyasında yoksa sırayla bakılacak daha genel anahtarları
+// döndürür; son eleman normal anahtardır. Sıra engine'in lookup sırasıyla aynıdır (keyCandidates):
+// her ad alanında önce kart sayısına özel, sonra genel anahtar; ad alanları splitNamespaceOrder'a göre.
+func (k StrategyKey) FallbackKeys() []StrategyKey {
+	namespaces := []string{}
+	for i, ns := range splitNamespaceOrder {
+		if ns == k.Namespace {
+			namespaces = splitNamespaceOrder[i:]
+			break
+		}
+	}
+	return keyCandidates(k.Base(), namespaces, k.Cards)
+}
+
+// keyCandidates, verilen ad alanlarında (ardından normal anahtarda) aranacak anahtarları sırayla
+// üretir. cards > 0 ise her ad alanında kart sayısına özel anahtar genel anahtardan önce gelir.
+func keyCandidates(base StrategyKey, namespaces []string, cards int) []StrategyKey {
+	keys := []StrategyKey{}
+	for _, ns := range append(append([]string{}, namespaces...), "") {
+		k := base
+		k.Namespace = ns
+		if cards > 0 && k.Kind != KeyKindPair {
+			k.Cards = cards
+			keys = append(keys, k)
+			k.Cards = 0
+		}
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// Total, soft/hard anahtarlar için el toplamını döndürür. Pair anahtarlarında 0 döner.
+func (k StrategyKey) Total() int {
+	if k.Kind == KeyKindPair {
+		return 0
+	}
+	v, _ := strconv.Atoi(k.Player)
+	return v
+}
+
+// ParseStrategyKey, anahtar grameriyle uyumlu bir string'i parçalarına ayırır.
+func ParseStrategyKey(key string) (StrategyKey, error) {
+	namespace, rest := "", key
+	for _, ns := range KeyNamespaces {
+		// En uzun ön ek kazanır: after_split_no_das_..., after_split_... ile de başlar
+		if strings.HasPrefix(key, ns+"_") && len(ns) > len(namespace) {
+			namespace, rest = ns, strings.TrimPrefix(key, ns+"_")
+		}
+	}
+
+	left, dealer, ok := strings.Cut(rest, "_vs_")
+	if !ok || dealer == "" {
+		return StrategyKey{}, fmt.Errorf("invalid strategy key %q: missing _vs_ part", key)
+	}
+	kind, player, ok := strings.Cut(left, "_")
+	if !ok || player == "" {
+		return StrategyKey{}, fmt.Errorf("invalid strategy key %q: missing hand part", key)
+	}
+
+	cards := 0
+	switch kind {
+	case KeyKindPair:
+		if !isRank(player) {
+			return StrategyKey{}, fmt.Errorf("invalid strategy key %q: unknown pair rank %q", key, player)
+		}
+	case KeyKindSoft, KeyKindHard:
+		total, count, hasCount := strings.Cut(player, "_")
+		if _, err := strconv.Atoi(total); err != nil {
+			return StrategyKey{}, fmt.Errorf("invalid strategy key %q: total %q is not a number", key, total)
+		}
+		if hasCount {
+			n, err := strconv.Atoi(strings.TrimSuffix(count, "cards"))
+			if err != nil || !strings.HasSuffix(count, "cards") || n < 2 {
+				return StrategyKey{}, fmt.Errorf("invalid strategy key %q: card count must look like 3cards", key)
+			}
+			player, cards = total, n
+		}
+	default:
+		return StrategyKey{}, fmt.Errorf("invalid strategy key %q: unknown hand kind %q", key, kind)
+	}
+
+	if !isRank(dealer) {
+		return StrategyKey{}, fmt.Errorf("invalid strategy key %q: unknown dealer rank %q", key, dealer)
+	}
+	return StrategyKey{Namespace: namespace, Kind: kind, Player: player, Cards: cards, Dealer: dealer}, nil
+}
+
+// ParseHoleCardKey, "hard_16_vs_hard_17" gibi dealer elinin tamamına bağlı bir anahtarı ayrıştırır.
+// Oyuncu kısmı normal anahtar grameriyle aynıdır; dealer kısmı iki kartlık elin türü ve toplamıdır
+// (hard 4-20, soft 12-21). Dealer blackjack'i yalnızca açık kart as iken önceden kontrol edilir;
+// açık kart onluk ve hole card as ise oyuncular yine karar verir ve el soft_21 olur.
+func ParseHoleCardKey(key string) (player StrategyKey, dealerKind string, dealerTotal int, err error) {
+	left, dealer, ok := strings.Cut(key, "_vs_")
+	if !ok || dealer == "" {
+		return StrategyKey{}, "", 0, fmt.Errorf("invalid hole card key %q: missing _vs_ part", key)
+	}
+	player, err = ParseStrategyKey(left + "_vs_2")
+	if err != nil || player.Cards > 0 || player.Namespace != "" {
+		return StrategyKey{}, "", 0, fmt.Errorf("invalid hole card key %q: unknown hand part %q", key, left)
+	}
+	kind, total, ok := strings.Cut(dealer, "_")
+	dealerTotal, convErr := strconv.Atoi(total)
+	if !ok || convErr != nil {
+		return StrategyKey{}, "", 0, fmt.Errorf("invalid hole card key %q: dealer hand must look like hard_17 or soft_17", key)
+	}
+	switch {
+	case kind == KeyKindHard && dealerTotal >= 4 && dealerTotal <= 20:
+	case kind == KeyKindSoft && dealerTotal >= 12 && dealerTotal <= 21:
+	default:
+		return StrategyKey{}, "", 0, fmt.Errorf("invalid hole card key %q: unreachable dealer hand %q", key, dealer)
+	}
+	return player, kind, dealerTotal, nil
+}
+
+// holeCardKey, hole card görüldüğünde el ve dealer'ın iki kartı için anahtarı üretir (ör. "hard_16_vs_soft_17")
+func holeCardKey(hand *Hand, dealerUp, hole Card) string {
+	left, _, _ := strings.Cut(strategyKey(hand, dealerUp), "_vs_")
+	dealer := &Hand{Cards: []Card{dealerUp, hole}}
+	kind := KeyKindHard
+	if dealerUp.Rank == "A" || hole.Rank == "A" {
+		kind = KeyKindSoft // iki kartta as her zaman 11 sayılır (A+A = soft 12)
+	}
+	return fmt.Sprintf("%s_vs_%s_%d", left, kind, dealer.CalculateValue())
+}
+
+// strategyKey, bir el ve dealer açık kartı için strateji anahtarını üretir. 11 sayılan bir as
+// içeren eller kart sayısından bağımsız olarak soft'tur (A,2,3 -> soft_16).
+func strategyKey(hand *Hand, dealerUp Card) string {
+	return handKey(hand, dealerUp).String()
+}
+
+// handKey, strategyKey'in ayrıştırılmış halidir
+func handKey(hand *Hand, dealerUp Card) StrategyKey {
+	dealerKey := getDealerRankKey(dealerUp)
+	if hand.CanSplit() {
+		return StrategyKey{Kind: KeyKindPair, Player: hand.Cards[0].Rank, Dealer: dealerKey}
+	}
+	kind := KeyKindHard
+	if hand.IsSoft() {
+		kind = KeyKindSoft
+	}
+	return StrategyKey{Kind: kind, Player: strconv.Itoa(hand.CalculateValue()), Dealer: dealerKey}
+}
+
+// splitNamespaces, split'ten gelen el için geçerli ad alanlarını splitNamespaceOrder sırasıyla
+// döndürür. Split'ten gelmeyen eller için boştur.
+func splitNamespaces(ctx *DecisionContext) []string {
+	if !ctx.Hand.IsSplitChild {
+		return nil
+	}
+	namespaces := []string{}
+	for _, ns := range splitNamespaceOrder {
+		switch {
+		case ns == KeyNamespaceAfterSplitNoResplit && !(ctx.Hand.CanSplit() && ctx.MaxSplitsReached):
+		case ns == KeyNamespaceAfterSplitNoDAS && ctx.AllowDAS:
+		default:
+			namespaces = append(namespaces, ns)
+		}
+	}
+	return namespaces
+}
+
+// cardCountKey, elin kart sayısına özel anahtarını üretir (ör. "hard_16_3cards_vs_10").
+// Çiftler her zaman iki kartlı olduğu için boş döner.
+func cardCountKey(hand *Hand, dealerUp Card) string {
+	if hand.CanSplit() {
+		return ""
+	}
+	k := handKey(hand, dealerUp)
+	k.Cards = len(hand.Cards)
+	return k.String()
+}
+
+func isRank(r string) bool {
+	for _, rank := range Ranks {
+		if rank == r {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,281 @@
+package engine
+
+import (
+	"reflect"
+	"testing"
+)
+
+// testHand, verilen rank'lardan bir el oluşturur
+func testHand(ranks ...string) *Hand {
+	h := &Hand{}
+	for _, r := range ranks {
+		h.Cards = append(h.Cards, Card{Rank: r, Suit: "Spades"})
+	}
+	return h
+}
+
+func TestHandIsSoft(t *testing.T) {
+	tests := []struct {
+		ranks []string
+		want  bool
+	}{
+		{[]string{"A", "6"}, true},
+		{[]string{"A", "2", "4"}, true},
+		{[]string{"A", "2", "3", "A"}, true},
+		{[]string{"A", "6", "10"}, false},
+		{[]string{"A", "A", "10"}, false},
+		{[]string{"10", "6"}, false},
+	}
+	for _, tt := range tests {
+		if got := testHand(tt.ranks...).IsSoft(); got != tt.want {
+			t.Errorf("IsSoft(%v) = %v, want %v", tt.ranks, got, tt.want)
+		}
+	}
+}
+
+func TestStrategyKey(t *testing.T) {
+	up := Card{Rank: "K"}
+	tests := []struct {
+		ranks []string
+		want  string
+	}{
+		{[]string{"A", "6"}, "soft_17_vs_10"},
+		{[]string{"A", "2", "4"}, "soft_17_vs_10"},
+		{[]string{"A", "6", "10"}, "hard_17_vs_10"},
+		{[]string{"8", "8"}, "pair_8_vs_10"},
+		{[]string{"A", "A"}, "pair_A_vs_10"},
+		{[]string{"10", "3", "3"}, "hard_16_vs_10"},
+	}
+	for _, tt := range tests {
+		if got := strategyKey(testHand(tt.ranks...), up); got != tt.want {
+			t.Errorf("strategyKey(%v) = %q, want %q", tt.ranks, got, tt.want)
+		}
+	}
+}
+
+func TestCardCountKey(t *testing.T) {
+	up := Card{Rank: "9"}
+	tests := []struct {
+		ranks []string
+		want  string
+	}{
+		{[]string{"10", "3", "3"}, "hard_16_3cards_vs_9"},
+		{[]string{"A", "2", "4"}, "soft_17_3cards_vs_9"},
+		{[]string{"10", "6"}, "hard_16_2cards_vs_9"},
+		{[]string{"8", "8"}, ""},
+	}
+	for _, tt := range tests {
+		if got := cardCountKey(testHand(tt.ranks...), up); got != tt.want {
+			t.Errorf("cardCountKey(%v) = %q, want %q", tt.ranks, got, tt.want)
+		}
+	}
+}
+
+// Üç kartlı soft eller de soft anahtarıyla aranır; double'ın yasal olup olmadığına masa kuralı karar verir
+func TestCountingStrategyMultiCardSoftHand(t *testing.T) {
+	s := &CountingStrategy{BaseStrategy: &DynamicStrategy{Fallback: "stand", Actions: map[string][]string{
+		"soft_17_vs_3": {"double", "hit"},
+		"soft_18_vs_3": {"double", "stand"},
+		"soft_19_vs_6": {"double"},
+	}}}
+	tests := []struct {
+		ranks     []string
+		up        string
+		canDouble bool
+		want      string
+	}{
+		{[]string{"A", "6"}, "3", true, "double"},
+		{[]string{"A", "2", "4"}, "3", true, "double"},
+		{[]string{"A", "2", "4"}, "3", false, "hit"},
+		{[]string{"A", "3", "4"}, "3", false, "stand"},
+		{[]string{"A", "4", "4"}, "6", false, "stand"},
+	}
+	for _, tt := range tests {
+		ctx := &DecisionContext{Hand: testHand(tt.ranks...), DealerUp: Card{Rank: tt.up}, CanDouble: tt.canDouble}
+		actions, _, _, _ := s.GetAction(ctx)
+		if got := ctx.FirstLegal(actions); got != tt.want {
+			t.Errorf("GetAction(%v vs %s, can double %v) plays %s (%v), want %s", tt.ranks, tt.up, tt.canDouble, got, actions, tt.want)
+		}
+	}
+}
+
+func TestParseStrategyKey(t *testing.T) {
+	tests := []struct {
+		key     string
+		want    StrategyKey
+		wantErr bool
+	}{
+		{key: "hard_16_vs_10", want: StrategyKey{Kind: "hard", Player: "16", Dealer: "10"}},
+		{key: "pair_A_vs_A", want: StrategyKey{Kind: "pair", Player: "A", Dealer: "A"}},
+		{key: "soft_18_3cards_vs_9", want: StrategyKey{Kind: "soft", Player: "18", Cards: 3, Dealer: "9"}},
+		{key: "after_split_hard_11_vs_6", want: StrategyKey{Namespace: "after_split", Kind: "hard", Player: "11", Dealer: "6"}},
+		{key: "after_split_no_das_hard_11_3cards_vs_6", want: StrategyKey{Namespace: "after_split_no_das", Kind: "hard", Player: "11", Cards: 3, Dealer: "6"}},
+		{key: "after_split_no_resplit_pair_8_vs_10", want: StrategyKey{Namespace: "after_split_no_resplit", Kind: "pair", Player: "8", Dealer: "10"}},
+		{key: "hard_16", wantErr: true},
+		{key: "hard_x_vs_10", wantErr: true},
+		{key: "pair_1_vs_10", wantErr: true},
+		{key: "pair_8_3cards_vs_10", wantErr: true},
+		{key: "hard_16_1cards_vs_10", wantErr: true},
+		{key: "hard_16_3_vs_10", wantErr: true},
+		{key: "hard_16_vs_1", wantErr: true},
+		{key: "after_split_foo_16_vs_10", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseStrategyKey(tt.key)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseStrategyKey(%q) = %+v, want error", tt.key, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseStrategyKey(%q) error: %v", tt.key, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseStrategyKey(%q) = %+v, want %+v", tt.key, got, tt.want)
+		}
+		if got.String() != tt.key {
+			t.Errorf("ParseStrategyKey(%q).String() = %q", tt.key, got.String())
+		}
+	}
+}
+
+func TestFallbackKeys(t *testing.T) {
+	tests := []struct {
+		key  string
+		want []string
+	}{
+		{"hard_16_vs_10", []string{"hard_16_vs_10"}},
+		{"hard_16_3cards_vs_10", []string{"hard_16_3cards_vs_10", "hard_16_vs_10"}},
+		{"after_split_no_resplit_pair_8_vs_10", []string{
+			"after_split_no_resplit_pair_8_vs_10", "after_split_no_das_pair_8_vs_10", "after_split_pair_8_vs_10", "pair_8_vs_10",
+		}},
+		{"after_split_no_das_hard_11_3cards_vs_6", []string{
+			"after_split_no_das_hard_11_3cards_vs_6", "after_split_no_das_hard_11_vs_6",
+			"after_split_hard_11_3cards_vs_6", "after_split_hard_11_vs_6",
+			"hard_11_3cards_vs_6", "hard_11_vs_6",
+		}},
+	}
+	for _, tt := range tests {
+		k, err := ParseStrategyKey(tt.key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := []string{}
+		for _, f := range k.FallbackKeys() {
+			got = append(got, f.String())
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("FallbackKeys(%q) = %v, want %v", tt.key, got, tt.want)
+		}
+	}
+}
+
+// Çalışma anındaki anahtar zinciri, ilk anahtarının FallbackKeys sırasını izlemelidir (diff aynı sırayı kullanır)
+func TestKeyChainMatchesFallbackKeys(t *testing.T) {
+	split := func(h *Hand) *Hand { h.IsSplitChild = true; return h }
+	s := &CountingStrategy{BaseStrategy: &DynamicStrategy{}, CardCountKeys: true}
+	contexts := []*DecisionContext{
+		{Hand: testHand("10", "6")},
+		{Hand: split(testHand("8", "8")), MaxSplitsReached: true},
+		{Hand: split(testHand("8", "8")), MaxSplitsReached: true, AllowDAS: true},
+		{Hand: split(testHand("5", "6")), AllowDAS: true},
+		{Hand: split(testHand("5", "3", "3"))},
+	}
+	for _, ctx := range contexts {
+		ctx.DealerUp = Card{Rank: "6"}
+		chain := s.keyChain(ctx)
+		k, err := ParseStrategyKey(chain[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		fallback := []string{}
+		for _, f := range k.FallbackKeys() {
+			fallback = append(fallback, f.String())
+		}
+		// FallbackKeys hand'in kurallarını bilmez; zincir onun sıralı bir alt kümesi olmalıdır
+		j := 0
+		for _, key := range fallback {
+			if j < len(chain) && chain[j] == key {
+				j++
+			}
+		}
+		if j != len(chain) {
+			t.Errorf("keyChain %v is not an ordered subset of FallbackKeys %v", chain, fallback)
+		}
+	}
+}
+
+func TestCountingStrategyKeyResolution(t *testing.T) {
+	deck := NewDeck(1, nil)
+	deck.RunningCount = 5 // tek deste, TC = 5
+	s := &CountingStrategy{
+		BaseStrategy: &DynamicStrategy{Fallback: "stand", Actions: map[string][]string{
+			"hard_16_vs_10":                       {"hit"},
+			"hard_16_3cards_vs_10":                {"hit"},
+			"after_split_hard_16_vs_10":           {"hit"},
+			"hard_11_vs_6":                        {"double", "hit"},
+			"after_split_hard_11_vs_6":            {"double", "hit"},
+			"after_split_no_das_hard_11_vs_6":     {"hit"},
+			"pair_8_vs_10":                        {"split"},
+			"after_split_no_resplit_pair_8_vs_10": {"stand"},
+		}},
+		Deviations: map[string]DeviationRule{
+			"hard_16_vs_10": {AtCount: 0, Action: "stand"},
+		},
+		CardCountKeys: true,
+		Deck:          deck,
+	}
+	split := func(h *Hand) *Hand { h.IsSplitChild = true; return h }
+	tests := []struct {
+		name    string
+		ctx     *DecisionContext
+		wantKey string
+		wantDev bool
+	}{
+		{"base deviation", &DecisionContext{Hand: testHand("10", "6"), DealerUp: Card{Rank: "K"}}, "hard_16_vs_10", true},
+		{"deviation under card count key", &DecisionContext{Hand: testHand("10", "3", "3"), DealerUp: Card{Rank: "K"}}, "hard_16_vs_10", true},
+		{"deviation under after_split key", &DecisionContext{Hand: split(testHand("10", "6")), DealerUp: Card{Rank: "K"}, AllowDAS: true}, "hard_16_vs_10", true},
+		{"after split with DAS", &DecisionContext{Hand: split(testHand("5", "6")), DealerUp: Card{Rank: "6"}, AllowDAS: true}, "after_split_hard_11_vs_6", false},
+		{"after split without DAS", &DecisionContext{Hand: split(testHand("5", "6")), DealerUp: Card{Rank: "6"}}, "after_split_no_das_hard_11_vs_6", false},
+		{"resplit capped", &DecisionContext{Hand: split(testHand("8", "8")), DealerUp: Card{Rank: "10"}, MaxSplitsReached: true, AllowDAS: true}, "after_split_no_resplit_pair_8_vs_10", false},
+		{"resplit allowed", &DecisionContext{Hand: split(testHand("8", "8")), DealerUp: Card{Rank: "10"}, AllowDAS: true}, "pair_8_vs_10", false},
+	}
+	for _, tt := range tests {
+		_, _, isDeviation, key := s.GetAction(tt.ctx)
+		if key != tt.wantKey || isDeviation != tt.wantDev {
+			t.Errorf("%s: key %q deviation %v, want %q %v", tt.name, key, isDeviation, tt.wantKey, tt.wantDev)
+		}
+	}
+}
+
+// Açık kart onluk ve hole card as iken dealer blackjack'i önceden kontrol edilmez; anahtar
+// üretilir ve ayrıştırılabilmelidir
+func TestHoleCardKey(t *testing.T) {
+	tests := []struct {
+		ranks    []string
+		up, hole string
+		want     string
+	}{
+		{[]string{"10", "6"}, "K", "7", "hard_16_vs_hard_17"},
+		{[]string{"10", "6"}, "6", "A", "hard_16_vs_soft_17"},
+		{[]string{"10", "6"}, "10", "A", "hard_16_vs_soft_21"},
+		{[]string{"8", "8"}, "A", "A", "pair_8_vs_soft_12"},
+	}
+	for _, tt := range tests {
+		got := holeCardKey(testHand(tt.ranks...), Card{Rank: tt.up}, Card{Rank: tt.hole})
+		if got != tt.want {
+			t.Errorf("holeCardKey(%v, %s, %s) = %q, want %q", tt.ranks, tt.up, tt.hole, got, tt.want)
+			continue
+		}
+		if _, _, _, err := ParseHoleCardKey(got); err != nil {
+			t.Errorf("ParseHoleCardKey(%q) error: %v", got, err)
+		}
+	}
+	for _, key := range []string{"hard_16_vs_hard_21", "hard_16_vs_soft_11", "hard_16_vs_hard_3", "hard_16_vs_10", "after_split_hard_16_vs_hard_17"} {
+		if _, _, _, err := ParseHoleCardKey(key); err == nil {
+			t.Errorf("ParseHoleCardKey(%q) accepted an unreachable key", key)
+		}
+	}
+}
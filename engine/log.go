@@ -77,6 +77,10 @@ func (l *Logger) writeHeader() {
 		"num_decks", "cut_card_position", "cards_drawn_total", "cards_drawn_round", "cards_left_after_round", 
 		"decision_trace",
 		"box_total_invested","box_total_earned",
+		"progression_state",
+		"player_rounds_played", "player_rounds_watched",
+		"side_count", "key_card_predictions", "hole_card_seen",
+		"perceived_running_count", "perceived_true_count",
 	})
 	l.writer.Flush()
 }
@@ -87,12 +91,12 @@ func (l *Logger) LogRound(round int, shoe int, box *Box, deck *Deck, dealer *Dea
 		l.headerWritten = true
 	}
 	p := box.Player
-	trueCount := 0
-	if deck != nil && deck.NumDecks > 0 {
-		remainingDecks := float64(len(deck.Cards)) / 52.0
-		if remainingDecks > 0 {
-			trueCount = int(float64(deck.RunningCount) / remainingDecks)
-		}
+	// Gerçek running count'tan stratejinin true_count politikasıyla hesaplanan true count; sayma
+	// hataları varsa oyuncunun algıladığı değerler ayrı sütunlara yazılır
+	trueCount := trueCountFor(p.Strategy, deck)
+	perceivedRC, perceivedTC := "", ""
+	if rc, tc, ok := perceivedCount(p.Strategy); ok {
+		perceivedRC, perceivedTC = strconv.Itoa(rc), formatTrueCount(p.Strategy, tc)
 	}
 	dealerUp := "?"
 	if len(dealer.Hand.Cards) > 0 {
@@ -129,7 +133,7 @@ func (l *Logger) LogRound(round int, shoe int, box *Box, deck *Deck, dealer *Dea
 			strconv.Itoa(round),
 			strconv.Itoa(shoe),
 			strconv.Itoa(int(float64(deck.RunningCount))),
-			strconv.Itoa(trueCount),
+			formatTrueCount(p.Strategy, trueCount),
 			strconv.Itoa(deck.RealCountTillCutCard),
 			box.ID,
 			strconv.Itoa(p.ID),
@@ -201,6 +205,15 @@ func (l *Logger) LogRound(round int, shoe int, box *Box, deck *Deck, dealer *Dea
 			record = append(record, "")
 			record = append(record, "")
 		}
+		record = append(record, box.ProgressionState)
+		record = append(record, strconv.Itoa(p.RoundsPlayed), strconv.Itoa(p.RoundsWatched))
+		sideCount, keyCards := "", ""
+		if cs, ok := countingStrategyOf(p.Strategy); ok {
+			sideCount = cs.SideCountState()
+			keyCards = cs.KeyCardState()
+		}
+		record = append(record, sideCount, keyCards, boolToStr(p.HoleCard != nil))
+		record = append(record, perceivedRC, perceivedTC)
 
 
 		l.writer.Write(record)
@@ -19,11 +19,18 @@ type Player struct {
 	TotalEarned     float64
 	BustedAtRound  int
 	RetiredAtRound int
+	Wonging        *config.WongingConfig // nil ise oyuncu her round oynar
+	IsSeated       bool                  // wonging oyuncusu şu an box'larında oynuyor mu
+	RoundsPlayed   int
+	RoundsWatched  int
+	HoleCardProbability float64 // dealer'ın hole card'ı bu oyuncuya gösterme olasılığı
+	HoleCard            *Card   // bu round'da görülen hole card (görülmediyse nil)
+	spreadProgressions  []BettingSystem // spread ile ödünç alınan box'ların progresyon durumları (ek el sırasıyla)
 }
 
 func NewPlayer(cfg config.PlayerConfig, strategy Strategy) *Player {
 	// Strategy'ye sigorta davranışını aktar
-	if cs, ok := strategy.(*CountingStrategy); ok {
+	if cs, ok := countingStrategyOf(strategy); ok {
 		cs.AcceptInsurance = cfg.AcceptInsurance
 	}
 
@@ -34,9 +41,28 @@ func NewPlayer(cfg config.PlayerConfig, strategy Strategy) *Player {
 		InitialBalance:  cfg.InitialBalance,
 		TargetBalance:   cfg.TargetBalance,
 		Strategy:        strategy,
+		Wonging:         cfg.Wonging,
+		HoleCardProbability: cfg.HoleCardProbability,
 	}
 }
 
+// UpdateWonging, round öncesi görülen true count'a göre wonging oyuncusunu oturtur ya da kaldırır
+func (p *Player) UpdateWonging(trueCount float64) {
+	if p.Wonging == nil {
+		return
+	}
+	if !p.IsSeated && trueCount >= p.Wonging.EnterAt {
+		p.IsSeated = true
+	} else if p.IsSeated && trueCount < p.Wonging.ExitBelow {
+		p.IsSeated = false
+	}
+}
+
+// IsWatching, wonging oyuncusunun bu round'u oturmadan izleyip izlemediğini söyler
+func (p *Player) IsWatching() bool {
+	return p.Wonging != nil && !p.IsSeated
+}
+
 func (p *Player) CanBet(amount float64) bool {
 	return p.Balance >= amount
 }
@@ -59,6 +85,7 @@ func (p *Player) ResetRound() {
 	p.RoundStartBal = p.Balance
 	p.TotalSpent = 0
 	p.TotalEarned = 0
+	p.HoleCard = nil
 }
 
 func (p *Player) CheckStatus(minBet float64, round int) {
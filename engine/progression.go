@@ -0,0 +1,259 @@
+package engine
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Progresyon sistemleri
+const (
+	SystemMartingale  = "martingale"
+	SystemParoli      = "paroli"
+	System1326        = "1-3-2-6"
+	SystemLabouchere  = "labouchere"
+	SystemDAlembert   = "dalembert"
+	SystemOscarsGrind = "oscars_grind"
+)
+
+// Progresyonun sayma bazlı bahisle nasıl birleşeceği
+const (
+	CombineMultiply = "multiply" // bet_ramp/betting sonucu x sistem çarpanı (varsayılan)
+	CombineReplace  = "replace"  // box main_bet x sistem çarpanı, bet_ramp yok sayılır
+)
+
+// ProgressionConfig, strateji dosyasındaki "progression" bölümüdür.
+type ProgressionConfig struct {
+	System   string    `json:"system"`
+	Combine  string    `json:"combine"`
+	MaxSteps int       `json:"max_steps"` // martingale: üst üste kayıp, paroli: üst üste kazanç limiti
+	Line     []float64 `json:"line"`      // labouchere başlangıç dizisi (varsayılan 1-2-3-4)
+}
+
+// BettingSystem, box'ın önceki sonuçlarına göre bir sonraki bahsi birim cinsinden belirler.
+// Her box kendi durumunu tutar.
+type BettingSystem interface {
+	NextUnits() float64
+	Record(netUnits float64) // ana ellerin net sonucu, round'un birim bahsi cinsinden
+	State() string
+}
+
+// NewBettingSystem, config'e göre yeni (sıfırlanmış) bir progresyon durumu oluşturur
+func NewBettingSystem(cfg *ProgressionConfig) (BettingSystem, error) {
+	switch cfg.System {
+	case SystemMartingale:
+		return &martingale{maxSteps: cfg.MaxSteps}, nil
+	case SystemParoli:
+		maxWins := cfg.MaxSteps
+		if maxWins <= 0 {
+			maxWins = 3
+		}
+		return &paroli{maxWins: maxWins}, nil
+	case System1326:
+		return &oneThreeTwoSix{}, nil
+	case SystemLabouchere:
+		line := cfg.Line
+		if len(line) == 0 {
+			line = []float64{1, 2, 3, 4}
+		}
+		l := &labouchere{initial: line}
+		l.reset()
+		return l, nil
+	case SystemDAlembert:
+		return &dalembert{units: 1}, nil
+	case SystemOscarsGrind:
+		return &oscarsGrind{units: 1}, nil
+	}
+	return nil, fmt.Errorf("unknown progression system %q", cfg.System)
+}
+
+// unitBet, combine ayarına göre progresyon çarpanının uygulanacağı birim bahsi seçer
+func (c *ProgressionConfig) unitBet(configBet, countingBet float64) float64 {
+	if c.Combine == CombineReplace {
+		return configBet
+	}
+	return countingBet
+}
+
+func validateProgression(c *ProgressionConfig) []ValidationIssue {
+	issues := []ValidationIssue{}
+	if c == nil {
+		return issues
+	}
+	if _, err := NewBettingSystem(c); err != nil {
+		issues = append(issues, ValidationIssue{Severity: SeverityError, Section: "progression", Message: err.Error()})
+	}
+	if c.Combine != "" && c.Combine != CombineMultiply && c.Combine != CombineReplace {
+		issues = append(issues, ValidationIssue{Severity: SeverityError, Section: "progression", Message: fmt.Sprintf("unknown combine mode %q", c.Combine)})
+	}
+	for _, v := range c.Line {
+		if v <= 0 {
+			issues = append(issues, ValidationIssue{Severity: SeverityError, Section: "progression", Message: "labouchere line values must be positive"})
+			break
+		}
+	}
+	return issues
+}
+
+// Martingale: her kayıpta bahsi ikiye katlar, kazançta başa döner
+type martingale struct {
+	losses   int
+	maxSteps int
+}
+
+func (m *martingale) NextUnits() float64 {
+	return math.Pow(2, float64(m.losses))
+}
+
+func (m *martingale) Record(net float64) {
+	switch {
+	case net < 0:
+		m.losses++
+		if m.maxSteps > 0 && m.losses >= m.maxSteps {
+			m.losses = 0 // limite ulaşıldı, kayıp kabul edilip başa dönülür
+		}
+	case net > 0:
+		m.losses = 0
+	}
+}
+
+func (m *martingale) State() string {
+	return fmt.Sprintf("martingale losses=%d units=%g", m.losses, m.NextUnits())
+}
+
+// Paroli: her kazançta bahsi ikiye katlar, maxWins kazanç ya da kayıpta başa döner
+type paroli struct {
+	wins    int
+	maxWins int
+}
+
+func (p *paroli) NextUnits() float64 {
+	return math.Pow(2, float64(p.wins))
+}
+
+func (p *paroli) Record(net float64) {
+	switch {
+	case net > 0:
+		p.wins++
+		if p.wins >= p.maxWins {
+			p.wins = 0
+		}
+	case net < 0:
+		p.wins = 0
+	}
+}
+
+func (p *paroli) State() string {
+	return fmt.Sprintf("paroli wins=%d units=%g", p.wins, p.NextUnits())
+}
+
+// 1-3-2-6: kazandıkça diziyi ilerletir, kayıpta ya da dizi bitince başa döner
+type oneThreeTwoSix struct {
+	step int
+}
+
+var sequence1326 = []float64{1, 3, 2, 6}
+
+func (o *oneThreeTwoSix) NextUnits() float64 {
+	return sequence1326[o.step]
+}
+
+func (o *oneThreeTwoSix) Record(net float64) {
+	switch {
+	case net > 0:
+		o.step = (o.step + 1) % len(sequence1326)
+	case net < 0:
+		o.step = 0
+	}
+}
+
+func (o *oneThreeTwoSix) State() string {
+	return fmt.Sprintf("1-3-2-6 step=%d units=%g", o.step+1, o.NextUnits())
+}
+
+// Labouchère: bahis dizinin ilk ve son elemanının toplamıdır; kazançta uçlar silinir,
+// kayıpta bahis dizinin sonuna eklenir. Dizi bitince başlangıç dizisine dönülür.
+type labouchere struct {
+	initial []float64
+	line    []float64
+}
+
+func (l *labouchere) reset() {
+	l.line = append([]float64{}, l.initial...)
+}
+
+func (l *labouchere) NextUnits() float64 {
+	if len(l.line) == 1 {
+		return l.line[0]
+	}
+	return l.line[0] + l.line[len(l.line)-1]
+}
+
+func (l *labouchere) Record(net float64) {
+	switch {
+	case net > 0:
+		if len(l.line) <= 2 {
+			l.reset()
+		} else {
+			l.line = l.line[1 : len(l.line)-1]
+		}
+	case net < 0:
+		l.line = append(l.line, l.NextUnits())
+	}
+}
+
+func (l *labouchere) State() string {
+	parts := make([]string, len(l.line))
+	for i, v := range l.line {
+		parts[i] = fmt.Sprintf("%g", v)
+	}
+	return fmt.Sprintf("labouchere line=%s units=%g", strings.Join(parts, "-"), l.NextUnits())
+}
+
+// D'Alembert: kayıpta bir birim artırır, kazançta bir birim azaltır (en az 1)
+type dalembert struct {
+	units float64
+}
+
+func (d *dalembert) NextUnits() float64 {
+	return d.units
+}
+
+func (d *dalembert) Record(net float64) {
+	switch {
+	case net < 0:
+		d.units++
+	case net > 0 && d.units > 1:
+		d.units--
+	}
+}
+
+func (d *dalembert) State() string {
+	return fmt.Sprintf("dalembert units=%g", d.units)
+}
+
+// Oscar's Grind: her seri +1 birim kâr hedefler. Kazançta bahis bir birim artar ama
+// hedefi aşacak kadar büyümez; kayıpta bahis değişmez.
+type oscarsGrind struct {
+	units  float64
+	profit float64
+}
+
+func (o *oscarsGrind) NextUnits() float64 {
+	return o.units
+}
+
+func (o *oscarsGrind) Record(net float64) {
+	o.profit += net
+	if o.profit >= 1 {
+		o.units, o.profit = 1, 0
+		return
+	}
+	if net > 0 {
+		o.units = math.Max(1, math.Min(o.units+1, 1-o.profit))
+	}
+}
+
+func (o *oscarsGrind) State() string {
+	return fmt.Sprintf("oscars_grind profit=%g units=%g", o.profit, o.units)
+}
@@ -0,0 +1,201 @@
+package engine
+
+import (
+	"encoding/csv"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"sort"
+	"strconv"
+
+	"simjack/config"
+)
+
+// RiskOptions, risk-of-ruin Monte Carlo çalıştırmasının ayarlarıdır.
+type RiskOptions struct {
+	Trials      int   // bağımsız bakiye yolu sayısı
+	SampleEvery int   // bakiye eğrisi için kaç round'da bir örnek alınacağı
+	Seed        int64 // 0 değilse deneme i için Seed+i kullanılır (tekrarlanabilir sonuçlar)
+}
+
+// BankrollPercentiles, belirli bir round'da tüm denemelerdeki bakiye dağılımıdır.
+type BankrollPercentiles struct {
+	Round int     `json:"round"`
+	P5    float64 `json:"p5"`
+	P25   float64 `json:"p25"`
+	P50   float64 `json:"p50"`
+	P75   float64 `json:"p75"`
+	P95   float64 `json:"p95"`
+	Mean  float64 `json:"mean"`
+}
+
+// RiskResult, bir oyuncu konfigürasyonu için Monte Carlo sonuçlarıdır.
+type RiskResult struct {
+	PlayerID             int                   `json:"player_id"`
+	Owner                string                `json:"owner"`
+	Strategy             string                `json:"strategy"`
+	InitialBalance       float64               `json:"initial_balance"`
+	TargetBalance        float64               `json:"target_balance"`
+	Trials               int                   `json:"trials"`
+	Ruined               int                   `json:"ruined"`
+	ReachedTarget        int                   `json:"reached_target"`
+	RiskOfRuin           float64               `json:"risk_of_ruin"`
+	TargetProbability    float64               `json:"target_probability"`
+	MedianRoundsToRuin   float64               `json:"median_rounds_to_ruin"`
+	MedianRoundsToTarget float64               `json:"median_rounds_to_target"`
+	Curve                []BankrollPercentiles `json:"-"`
+}
+
+// RunRiskAnalysis, aynı config ile Trials kadar bağımsız simülasyon çalıştırır (her biri yeni
+// karıştırılmış shoe ve taze Engine ile) ve her oyuncu için iflas/hedef olasılıklarını ve
+// bakiye yüzdelik eğrilerini hesaplar. Log yazılmaz. strategies nil değilse stratejiler stdin ile
+// verilen bu paketten, aksi halde strateji dizininden okunur.
+func RunRiskAnalysis(cfg config.SimulationConfig, strategies map[string]CountingStrategyFile, opts RiskOptions) ([]RiskResult, error) {
+	if opts.Trials < 1 {
+		return nil, fmt.Errorf("trials must be positive")
+	}
+	if opts.SampleEvery < 1 {
+		opts.SampleEvery = 1
+	}
+
+	// Stratejiler bir kez okunup çözülür ve doğrulanır; denemeler yalnızca taze örnekler oluşturur
+	strategies, err := loadPlayerStrategies(cfg, strategies)
+	if err != nil {
+		return nil, err
+	}
+
+	samples := cfg.RoundCount/opts.SampleEvery + 1
+	numPlayers := len(cfg.Players)
+	// balances[oyuncu][örnek][deneme]
+	balances := make([][][]float64, numPlayers)
+	for i := range balances {
+		balances[i] = make([][]float64, samples)
+		for j := range balances[i] {
+			balances[i][j] = make([]float64, opts.Trials)
+		}
+	}
+	ruinRounds := make([][]float64, numPlayers)
+	targetRounds := make([][]float64, numPlayers)
+
+	var names []string
+	for t := 0; t < opts.Trials; t++ {
+		if opts.Seed != 0 {
+			rand.Seed(opts.Seed + int64(t))
+		}
+		e, err := newEngine(cfg, nil, false, false, strategies)
+		if err != nil {
+			return nil, err
+		}
+		if names == nil {
+			for _, p := range e.Players {
+				names = append(names, p.Strategy.String())
+			}
+		}
+
+		sample := func(idx int) {
+			for i, p := range e.Players {
+				balances[i][idx][t] = p.Balance
+			}
+		}
+		sample(0)
+		for r := 1; r <= cfg.RoundCount; r++ {
+			// Oyuncular bittiğinde bakiyeleri sabit kalır, örnekleme devam eder
+			e.Step()
+			if r%opts.SampleEvery == 0 {
+				sample(r / opts.SampleEvery)
+			}
+		}
+
+		for i, p := range e.Players {
+			if p.IsBusted {
+				ruinRounds[i] = append(ruinRounds[i], float64(p.BustedAtRound))
+			}
+			if p.IsRetired {
+				targetRounds[i] = append(targetRounds[i], float64(p.RetiredAtRound))
+			}
+		}
+	}
+
+	results := []RiskResult{}
+	for i, pc := range cfg.Players {
+		res := RiskResult{
+			PlayerID:             pc.PlayerID,
+			Owner:                pc.Owner,
+			Strategy:             names[i],
+			InitialBalance:       pc.InitialBalance,
+			TargetBalance:        pc.TargetBalance,
+			Trials:               opts.Trials,
+			Ruined:               len(ruinRounds[i]),
+			ReachedTarget:        len(targetRounds[i]),
+			RiskOfRuin:           float64(len(ruinRounds[i])) / float64(opts.Trials),
+			TargetProbability:    float64(len(targetRounds[i])) / float64(opts.Trials),
+			MedianRoundsToRuin:   percentile(ruinRounds[i], 50),
+			MedianRoundsToTarget: percentile(targetRounds[i], 50),
+		}
+		for j := range balances[i] {
+			vals := balances[i][j]
+			sum := 0.0
+			for _, v := range vals {
+				sum += v
+			}
+			res.Curve = append(res.Curve, BankrollPercentiles{
+				Round: j * opts.SampleEvery,
+				P5:    percentile(vals, 5),
+				P25:   percentile(vals, 25),
+				P50:   percentile(vals, 50),
+				P75:   percentile(vals, 75),
+				P95:   percentile(vals, 95),
+				Mean:  sum / float64(len(vals)),
+			})
+		}
+		results = append(results, res)
+	}
+	return results, nil
+}
+
+// percentile, değerlerin p. yüzdeliğini doğrusal interpolasyonla döndürür (boşsa NaN yerine 0)
+func percentile(values []float64, p float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64{}, values...)
+	sort.Float64s(sorted)
+	pos := p / 100 * float64(len(sorted)-1)
+	lo := int(math.Floor(pos))
+	hi := int(math.Ceil(pos))
+	if lo == hi {
+		return sorted[lo]
+	}
+	return sorted[lo] + (sorted[hi]-sorted[lo])*(pos-float64(lo))
+}
+
+// WriteRiskCurvesCSV, tüm oyuncuların bakiye yüzdelik eğrilerini grafik için tek bir CSV'ye yazar
+func WriteRiskCurvesCSV(path string, results []RiskResult) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := csv.NewWriter(f)
+	w.Write([]string{"player_id", "owner", "strategy", "round", "p5", "p25", "p50", "p75", "p95", "mean"})
+	for _, res := range results {
+		for _, c := range res.Curve {
+			w.Write([]string{
+				strconv.Itoa(res.PlayerID),
+				res.Owner,
+				res.Strategy,
+				strconv.Itoa(c.Round),
+				fmt.Sprintf("%.2f", c.P5),
+				fmt.Sprintf("%.2f", c.P25),
+				fmt.Sprintf("%.2f", c.P50),
+				fmt.Sprintf("%.2f", c.P75),
+				fmt.Sprintf("%.2f", c.P95),
+				fmt.Sprintf("%.2f", c.Mean),
+			})
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
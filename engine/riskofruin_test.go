@@ -0,0 +1,36 @@
+package engine
+
+import (
+	"strings"
+	"testing"
+
+	"simjack/config"
+)
+
+// Hatalı strateji süreci sonlandırmak yerine hata olarak dönmelidir
+func TestRiskAnalysisReportsStrategyErrors(t *testing.T) {
+	cfg := config.SimulationConfig{
+		NumDecks:   1,
+		RoundCount: 10,
+		Players:    []config.PlayerConfig{{PlayerID: 1, InitialBalance: 100, Strategy: "bad", Boxes: []config.BoxAssignment{{Index: 1, MainBet: 1}}}},
+	}
+	tests := []struct {
+		bundle map[string]CountingStrategyFile
+		want   string
+	}{
+		{map[string]CountingStrategyFile{}, "strategy bad not found"},
+		{map[string]CountingStrategyFile{"bad": {Fallback: "dobule"}}, "invalid strategy bad"},
+	}
+	for _, tt := range tests {
+		_, err := RunRiskAnalysis(cfg, tt.bundle, RiskOptions{Trials: 2})
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("RunRiskAnalysis error %v, want %q", err, tt.want)
+		}
+	}
+
+	good := map[string]CountingStrategyFile{"bad": {Fallback: "stand"}}
+	results, err := RunRiskAnalysis(cfg, good, RiskOptions{Trials: 3})
+	if err != nil || len(results) != 1 || results[0].Trials != 3 {
+		t.Errorf("RunRiskAnalysis = %+v, %v", results, err)
+	}
+}
@@ -0,0 +1,274 @@
+package engine
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// ScriptConfig, strateji dosyasındaki "script" bölümüdür. Her alan isteğe bağlı bir ifadedir;
+// boş bırakılan karar için counting stratejisinin kendi davranışı kullanılır. base() her
+// ifadede o kararın counting stratejisindeki sonucunu döndürür.
+type ScriptConfig struct {
+	Action    string `json:"action,omitempty"`    // aksiyon listesi ("double,hit" gibi virgülle ayrılmış) döndürür
+	Bet       string `json:"bet,omitempty"`       // round bahsini döndürür
+	Insurance string `json:"insurance,omitempty"` // sigorta alınıp alınmayacağını döndürür
+}
+
+// ScriptStrategy, counting stratejisinin kararlarını script ifadeleriyle değiştirir.
+// Sayım, bahis modları, spread ve diğer tüm ayarlar gömülü CountingStrategy'den gelir.
+type ScriptStrategy struct {
+	*CountingStrategy
+	Script    *ScriptConfig
+	action    exprNode
+	bet       exprNode
+	insurance exprNode
+
+	// İfade ortamları NewScriptStrategy'de bir kez kurulur; her karar yalnızca değişkenleri ve
+	// base/legal/seen fonksiyonlarının okuduğu aşağıdaki alanları günceller
+	actionEnv     *exprEnv
+	betEnv        *exprEnv
+	insuranceEnv  *exprEnv
+	ctx           *DecisionContext
+	baseActions   string
+	baseBet       float64
+	baseInsurance bool
+}
+
+// Script ifadelerinin her birinin gördüğü değişkenler. Sayım değişkenleri (tc, rc, decks)
+// stratejinin algıladığı değerlerdir; tc aksiyonda oyun, bahiste bahis, sigortada sigorta
+// true count'udur (side count düzeltmeleri dahil).
+var (
+	scriptCountVars  = map[string]exprType{"tc": typeNumber, "rc": typeNumber, "decks": typeNumber, "cards_dealt": typeNumber}
+	scriptActionVars = map[string]exprType{
+		"hand.total": typeNumber, "hand.soft": typeBool, "hand.pair": typeBool, "hand.cards": typeNumber,
+		"dealer.up": typeNumber, "dealer.rank": typeString, "key": typeString,
+		"can.double": typeBool, "can.split": typeBool, "can.surrender": typeBool,
+		"split_count": typeNumber, "hands_in_box": typeNumber, "balance": typeNumber, "round": typeNumber,
+	}
+	scriptBetVars = map[string]exprType{"unit": typeNumber, "bankroll": typeNumber}
+)
+
+// newScriptEnv, bir ifade türü için değişkenleri sıfır değerleriyle, fonksiyonları da base()
+// sonucunun türüyle oluşturur. Çağrıya özel fonksiyonlar (base, legal, seen) derleme için yalnızca
+// türleriyle tanımlıdır; NewScriptStrategy bunları stratejinin karar durumuna bağlar.
+func newScriptEnv(extra map[string]exprType, baseType exprType) *exprEnv {
+	env := &exprEnv{vars: map[string]exprValue{}, funcs: map[string]exprFunc{}}
+	for _, vars := range []map[string]exprType{scriptCountVars, extra} {
+		for name, t := range vars {
+			env.vars[name] = exprValue{typ: t}
+		}
+	}
+	for name, f := range mathFuncs {
+		env.funcs[name] = f
+	}
+	env.funcs["base"] = exprFunc{result: baseType, call: func([]exprValue) exprValue { return exprValue{typ: baseType} }}
+	return env
+}
+
+func newActionEnv() *exprEnv {
+	env := newScriptEnv(scriptActionVars, typeString)
+	env.funcs["legal"] = exprFunc{params: []exprType{typeString}, result: typeBool}
+	env.funcs["seen"] = exprFunc{params: []exprType{typeString}, result: typeNumber}
+	return env
+}
+
+func newBetEnv() *exprEnv       { return newScriptEnv(scriptBetVars, typeNumber) }
+func newInsuranceEnv() *exprEnv { return newScriptEnv(nil, typeBool) }
+
+// compileScript, script ifadelerini derler; hatalar alan adıyla birlikte döndürülür
+func compileScript(c *ScriptConfig) (action, bet, insurance exprNode, err error) {
+	fields := []struct {
+		name string
+		src  string
+		env  *exprEnv
+		want exprType
+		out  *exprNode
+	}{
+		{"action", c.Action, newActionEnv(), typeString, &action},
+		{"bet", c.Bet, newBetEnv(), typeNumber, &bet},
+		{"insurance", c.Insurance, newInsuranceEnv(), typeBool, &insurance},
+	}
+	for _, f := range fields {
+		if strings.TrimSpace(f.src) == "" {
+			continue
+		}
+		node, cerr := compileExpr(f.src, f.env, f.want)
+		if cerr != nil {
+			return nil, nil, nil, fmt.Errorf("%s: %w", f.name, cerr)
+		}
+		*f.out = node
+	}
+	return action, bet, insurance, nil
+}
+
+// NewScriptStrategy, counting stratejisini script ifadeleriyle sarar
+func NewScriptStrategy(cs *CountingStrategy, c *ScriptConfig) (*ScriptStrategy, error) {
+	action, bet, insurance, err := compileScript(c)
+	if err != nil {
+		return nil, fmt.Errorf("invalid script in strategy %s: %w", cs.Name, err)
+	}
+	s := &ScriptStrategy{CountingStrategy: cs, Script: c, action: action, bet: bet, insurance: insurance}
+
+	s.actionEnv = newActionEnv()
+	s.actionEnv.funcs["base"] = exprFunc{result: typeString, call: func([]exprValue) exprValue { return stringValue(s.baseActions) }}
+	s.actionEnv.funcs["legal"] = exprFunc{params: []exprType{typeString}, result: typeBool, call: func(a []exprValue) exprValue {
+		return boolValue(s.ctx.IsLegal(a[0].str))
+	}}
+	s.actionEnv.funcs["seen"] = exprFunc{params: []exprType{typeString}, result: typeNumber, call: func(a []exprValue) exprValue {
+		return numberValue(float64(countRank(s.ctx.Visible.All(), a[0].str)))
+	}}
+	s.betEnv = newBetEnv()
+	s.betEnv.funcs["base"] = exprFunc{result: typeNumber, call: func([]exprValue) exprValue { return numberValue(s.baseBet) }}
+	s.insuranceEnv = newInsuranceEnv()
+	s.insuranceEnv.funcs["base"] = exprFunc{result: typeBool, call: func([]exprValue) exprValue { return boolValue(s.baseInsurance) }}
+	return s, nil
+}
+
+// setCountVars, sayım değişkenlerini stratejinin algıladığı değerlerle doldurur
+func (s *ScriptStrategy) setCountVars(env *exprEnv, tc float64) {
+	env.vars["tc"] = numberValue(tc)
+	if s.Deck == nil {
+		return
+	}
+	rc, policy := s.Deck.RunningCount, s.TrueCount
+	if s.counter != nil {
+		rc = s.counter.RunningCount
+		policy = s.TrueCount.withOverrides(s.CountingErrors.DeckEstimation, s.CountingErrors.TCRounding)
+	}
+	env.vars["rc"] = numberValue(float64(rc))
+	env.vars["decks"] = numberValue(policy.remainingDecks(s.Deck))
+	env.vars["cards_dealt"] = numberValue(float64(s.Deck.DrawnThisShoe))
+}
+
+func (s *ScriptStrategy) GetAction(ctx *DecisionContext) ([]string, bool, bool, string) {
+	actions, isFallback, isDeviation, key := s.CountingStrategy.GetAction(ctx)
+	if s.action == nil {
+		return actions, isFallback, isDeviation, key
+	}
+
+	hand, env := ctx.Hand, s.actionEnv
+	s.setCountVars(env, s.getTrueCount())
+	env.vars["hand.total"] = numberValue(float64(hand.CalculateValue()))
+	env.vars["hand.soft"] = boolValue(hand.IsSoft())
+	env.vars["hand.pair"] = boolValue(hand.CanSplit())
+	env.vars["hand.cards"] = numberValue(float64(len(hand.Cards)))
+	env.vars["dealer.up"] = numberValue(float64(ctx.DealerUp.Value()))
+	env.vars["dealer.rank"] = stringValue(getDealerRankKey(ctx.DealerUp))
+	env.vars["key"] = stringValue(key)
+	env.vars["can.double"] = boolValue(ctx.CanDouble)
+	env.vars["can.split"] = boolValue(ctx.CanSplit)
+	env.vars["can.surrender"] = boolValue(ctx.CanSurrender)
+	env.vars["split_count"] = numberValue(float64(ctx.SplitCount))
+	env.vars["hands_in_box"] = numberValue(float64(ctx.HandsInBox))
+	env.vars["balance"] = numberValue(ctx.Balance)
+	env.vars["round"] = numberValue(float64(ctx.Round))
+
+	baseResult := strings.Join(actions, ",")
+	s.ctx, s.baseActions = ctx, baseResult
+	result := s.action.eval(env).str
+	s.ctx = nil
+	if result == baseResult {
+		return actions, isFallback, isDeviation, key
+	}
+	scripted := []string{}
+	for _, a := range strings.Split(result, ",") {
+		if a = strings.TrimSpace(a); a != "" {
+			scripted = append(scripted, a)
+		}
+	}
+	// Script'in counting stratejisinden farklı kararı deviation olarak işaretlenir
+	return scripted, false, true, key
+}
+
+func (s *ScriptStrategy) GetBetUnit(base float64, bankroll float64) float64 {
+	baseBet := s.CountingStrategy.GetBetUnit(base, bankroll)
+	if s.bet == nil {
+		return baseBet
+	}
+	env := s.betEnv
+	s.setCountVars(env, s.bettingTrueCount())
+	env.vars["unit"] = numberValue(base)
+	env.vars["bankroll"] = numberValue(bankroll)
+	s.baseBet = baseBet
+	bet := s.bet.eval(env).num
+	if math.IsNaN(bet) || math.IsInf(bet, 0) {
+		return baseBet // geçersiz sonuç bakiyeyi bozmasın, stratejinin kendi bahsi kullanılır
+	}
+	if bet < 0 {
+		return 0
+	}
+	return s.Betting.round(bet)
+}
+
+func (s *ScriptStrategy) DecideInsurance() bool {
+	baseDecision := s.CountingStrategy.DecideInsurance()
+	if s.insurance == nil {
+		return baseDecision
+	}
+	env := s.insuranceEnv
+	s.setCountVars(env, s.insuranceTrueCount())
+	s.baseInsurance = baseDecision
+	return s.insurance.eval(env).b
+}
+
+// countRank, kartlar arasında rank'tan kaç tane olduğunu sayar ("10" tüm onlukları kapsar)
+func countRank(cards []Card, rank string) int {
+	rank = sideCountRank(rank)
+	n := 0
+	for _, c := range cards {
+		if sideCountRank(c.Rank) == rank {
+			n++
+		}
+	}
+	return n
+}
+
+// countingStrategyOf, stratejinin altındaki CountingStrategy'yi döndürür (script stratejileri dahil)
+func countingStrategyOf(s Strategy) (*CountingStrategy, bool) {
+	switch st := s.(type) {
+	case *CountingStrategy:
+		return st, true
+	case *ScriptStrategy:
+		return st.CountingStrategy, true
+	}
+	return nil, false
+}
+
+// validateScript, script ifadelerinin ayrıştırılıp tür denetiminden geçtiğini kontrol eder
+func validateScript(c *ScriptConfig) []ValidationIssue {
+	issues := []ValidationIssue{}
+	if c == nil {
+		return issues
+	}
+	action, _, _, err := compileScript(c)
+	if err != nil {
+		field, msg, _ := strings.Cut(err.Error(), ": ")
+		return append(issues, ValidationIssue{Severity: SeverityError, Section: "script", Key: field, Message: msg})
+	}
+	if action == nil {
+		return issues
+	}
+	for _, literal := range resultLiterals(action) {
+		for _, a := range strings.Split(literal, ",") {
+			if a = strings.TrimSpace(a); !isKnownAction(a) {
+				issues = append(issues, ValidationIssue{Severity: SeverityError, Section: "script", Key: "action", Message: fmt.Sprintf("unknown action %q%s", a, suggestAction(a))})
+			}
+		}
+	}
+	return issues
+}
+
+// resultLiterals, ifadenin doğrudan sonuç olarak döndürebileceği string sabitlerini toplar
+// (ternary dallarıyla birlikte); yazım hatası olan aksiyonlar yüklemede yakalanır.
+func resultLiterals(node exprNode) []string {
+	switch n := node.(type) {
+	case *literalNode:
+		if n.value.typ == typeString {
+			return []string{n.value.str}
+		}
+	case *ternaryNode:
+		return append(resultLiterals(n.then), resultLiterals(n.otherwise)...)
+	}
+	return nil
+}
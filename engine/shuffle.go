@@ -0,0 +1,156 @@
+package engine
+
+import (
+	"fmt"
+	"math/rand"
+
+	"simjack/config"
+)
+
+// Karıştırma modelleri
+const (
+	ShuffleRandom = "random" // kusursuz rastgele karıştırma (varsayılan)
+	ShuffleRiffle = "riffle" // tüm shoe'ya riffle + strip cut
+	ShuffleZone   = "zone"   // shoe bölgelere ayrılır, her bölge kendi içinde karıştırılıp sırası korunarak birleştirilir
+)
+
+// ShuffleObserver, yeni shoe karıştırılırken önceki shoe'nun discard sırasını almak isteyen
+// DeckObserver'lardır (ör. shuffle tracking yapan bir strateji). İlk shoe'da discards boştur.
+// newPositions[i], previousDiscards[i]'nin yeni shoe'daki konumudur; karıştırma sırayı
+// izlenebilir biçimde korumuyorsa (random model, zorunlu kartlar) nil'dir.
+type ShuffleObserver interface {
+	OnNewShoe(previousDiscards []Card, newPositions []int)
+}
+
+// ValidateShuffleConfig, config'teki karıştırma modelini kontrol eder
+func ValidateShuffleConfig(cfg *config.ShuffleConfig) error {
+	if cfg == nil {
+		return nil
+	}
+	switch cfg.Model {
+	case "", ShuffleRandom, ShuffleRiffle, ShuffleZone:
+	default:
+		return fmt.Errorf("unknown shuffle model %q", cfg.Model)
+	}
+	if cfg.Riffles < 0 || cfg.StripCuts < 0 || cfg.StripPackets < 0 || cfg.Zones < 0 {
+		return fmt.Errorf("shuffle riffles, strip_cuts, strip_packets and zones must not be negative")
+	}
+	if cfg.Clumping < 0 {
+		return fmt.Errorf("shuffle clumping must not be negative")
+	}
+	return nil
+}
+
+// shuffleIsRandom, modelin kusursuz karıştırma olup olmadığını döndürür
+func shuffleIsRandom(cfg *config.ShuffleConfig) bool {
+	return cfg == nil || cfg.Model == "" || cfg.Model == ShuffleRandom
+}
+
+// applyShuffleModel, n kartlık önceki shoe sırasından (yakılanlar + discard'lar + dağıtılmamış
+// kartlar) modele göre yeni shoe'nun permütasyonunu üretir: yeni shoe'nun i. kartı önceki sıranın
+// order[i]. kartıdır. Kusursuz olmayan karıştırmalar sırayı kısmen koruduğu için shuffle tracking
+// mümkün olur.
+func applyShuffleModel(cfg *config.ShuffleConfig, n int) []int {
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	if cfg.Model != ShuffleZone {
+		return handShuffle(cfg, order)
+	}
+
+	zones := cfg.Zones
+	if zones <= 0 {
+		zones = 2
+	}
+	out := make([]int, 0, n)
+	size := (n + zones - 1) / zones
+	for start := 0; start < n; start += size {
+		end := start + size
+		if end > n {
+			end = n
+		}
+		out = append(out, handShuffle(cfg, order[start:end])...)
+	}
+	return out
+}
+
+// handShuffle, kartlara Riffles kez riffle ve StripCuts kez strip cut uygular
+func handShuffle(cfg *config.ShuffleConfig, cards []int) []int {
+	riffles := cfg.Riffles
+	if riffles == 0 {
+		riffles = 3
+	}
+	packets := cfg.StripPackets
+	if packets == 0 {
+		packets = 5
+	}
+	for i := 0; i < riffles; i++ {
+		cards = riffle(cards, cfg.Clumping)
+	}
+	for i := 0; i < cfg.StripCuts; i++ {
+		cards = stripCut(cards, packets)
+	}
+	return cards
+}
+
+// riffle, Gilbert–Shannon–Reeds modeliyle tek bir riffle yapar: kartlar binom dağılımıyla iki
+// pakete ayrılır, her adımda paketlerden biri kalan kart sayısıyla orantılı olasılıkla seçilir.
+// clumping > 1 ise paketten tek kart yerine ortalaması clumping olan kümeler düşer.
+func riffle(cards []int, clumping float64) []int {
+	cut := 0
+	for range cards {
+		if rand.Intn(2) == 0 {
+			cut++
+		}
+	}
+	left, right := cards[:cut], cards[cut:]
+	out := make([]int, 0, len(cards))
+	for len(left) > 0 || len(right) > 0 {
+		k := clumpSize(clumping)
+		if rand.Intn(len(left)+len(right)) < len(left) {
+			k = min(k, len(left))
+			out = append(out, left[:k]...)
+			left = left[k:]
+		} else {
+			k = min(k, len(right))
+			out = append(out, right[:k]...)
+			right = right[k:]
+		}
+	}
+	return out
+}
+
+// clumpSize, ortalaması clumping olan geometrik dağılımdan küme boyutu çeker (en az 1)
+func clumpSize(clumping float64) int {
+	k := 1
+	if clumping <= 1 {
+		return k
+	}
+	for rand.Float64() < 1-1/clumping {
+		k++
+	}
+	return k
+}
+
+// stripCut, üstten yaklaşık eşit boyutlu paketler alıp yeni bir yığının üstüne koyar;
+// paketlerin sırası tersine döner, paket içi sıra korunur.
+func stripCut(cards []int, packets int) []int {
+	avg := len(cards) / packets
+	if avg < 1 {
+		avg = 1
+	}
+	out := make([]int, 0, len(cards))
+	for len(cards) > 0 {
+		size := avg/2 + rand.Intn(avg+1)
+		if size < 1 {
+			size = 1
+		}
+		if size > len(cards) {
+			size = len(cards)
+		}
+		out = append(append([]int{}, cards[:size]...), out...)
+		cards = cards[size:]
+	}
+	return out
+}
@@ -0,0 +1,133 @@
+package engine
+
+import (
+	"reflect"
+	"testing"
+
+	"simjack/config"
+)
+
+func TestApplyShuffleModelIsPermutation(t *testing.T) {
+	configs := []*config.ShuffleConfig{
+		{Model: ShuffleRiffle},
+		{Model: ShuffleRiffle, Riffles: 1, StripCuts: 2, Clumping: 3},
+		{Model: ShuffleZone, Zones: 3, StripCuts: 1},
+	}
+	for _, cfg := range configs {
+		order := applyShuffleModel(cfg, 312)
+		seen := make([]bool, 312)
+		for _, from := range order {
+			if seen[from] {
+				t.Fatalf("%+v: index %d appears twice", cfg, from)
+			}
+			seen[from] = true
+		}
+		if len(order) != 312 {
+			t.Errorf("%+v: %d indices, want 312", cfg, len(order))
+		}
+	}
+}
+
+// shuffleRecorder, SetupShoe'nun gözlemcilere verdiği discard'ları ve yeni konumlarını kaydeder
+type shuffleRecorder struct {
+	discards  []Card
+	positions []int
+}
+
+func (r *shuffleRecorder) OnNewShoe(previousDiscards []Card, newPositions []int) {
+	r.discards = append([]Card{}, previousDiscards...)
+	r.positions = newPositions
+}
+func (r *shuffleRecorder) OnCardDealt(c Card)           {}
+func (r *shuffleRecorder) OnShuffle()                   {}
+func (r *shuffleRecorder) OnCardsReturned(cards []Card) {}
+
+// Yakılan kartlar önceki shoe'nun başında olduğu için discard konumları onlar kadar kaymalıdır
+func TestSetupShoeReportsDiscardPositions(t *testing.T) {
+	d := &Deck{NumDecks: 1, BurnCards: 3, Shuffle: &config.ShuffleConfig{Model: ShuffleRiffle, StripCuts: 1}}
+	d.SetupShoe()
+	rec := &shuffleRecorder{}
+	d.AddObserver(rec)
+	for i := 0; i < 30; i++ {
+		if _, err := d.DealCard(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	d.SetupShoe()
+
+	if len(rec.discards) != 30 || len(rec.positions) != 30 {
+		t.Fatalf("got %d discards and %d positions, want 30", len(rec.discards), len(rec.positions))
+	}
+	// Yeni shoe: önce bu karıştırmada yakılan kartlar, sonra dağıtılacak kartlar
+	shoe := append(append([]Card{}, d.burned...), d.Cards...)
+	for i, c := range rec.discards {
+		if got := shoe[rec.positions[i]]; got != c {
+			t.Errorf("discard %d (%s) reported at position %d, which holds %s", i, c, rec.positions[i], got)
+		}
+	}
+}
+
+func TestSetupShoeRandomShuffleHasNoPositions(t *testing.T) {
+	d := &Deck{NumDecks: 1}
+	d.SetupShoe()
+	rec := &shuffleRecorder{}
+	d.AddObserver(rec)
+	for i := 0; i < 10; i++ {
+		d.DealCard()
+	}
+	d.SetupShoe()
+	if len(rec.discards) != 10 || rec.positions != nil {
+		t.Errorf("got %d discards, positions %v; want 10 discards and no positions", len(rec.discards), rec.positions)
+	}
+}
+
+func TestSlugRegion(t *testing.T) {
+	tests := []struct {
+		positions []int
+		want      [2]int
+	}{
+		{[]int{5, 6, 7}, [2]int{5, 8}},
+		{[]int{40, 41, 42, 43, 44, 45, 46, 47, 48, 300}, [2]int{41, 49}},
+		{[]int{9, 3, 4, 5, 6, 7, 8, 0, 1, 2}, [2]int{1, 9}},
+	}
+	for _, tt := range tests {
+		if got := slugRegion(tt.positions); got != tt.want {
+			t.Errorf("slugRegion(%v) = %v, want %v", tt.positions, got, tt.want)
+		}
+	}
+}
+
+func TestShuffleTrackerMapsSlugs(t *testing.T) {
+	tracker := &shuffleTracker{config: &ShuffleTrackingConfig{SegmentCards: 4, SlugCount: -3}}
+	discards := []Card{{Rank: "2"}, {Rank: "3"}, {Rank: "9"}, {Rank: "8"}, {Rank: "A"}, {Rank: "K"}, {Rank: "10"}, {Rank: "Q"}}
+	positions := []int{10, 11, 12, 13, 30, 31, 32, 33}
+
+	tracker.OnNewShoe(discards, positions)
+	if want := [][2]int{{30, 34}}; !reflect.DeepEqual(tracker.slugs, want) {
+		t.Errorf("slugs = %v, want %v", tracker.slugs, want)
+	}
+	if tracker.inSlug(4) || !tracker.inSlug(30) || tracker.inSlug(34) {
+		t.Errorf("inSlug does not follow the moved slug %v", tracker.slugs)
+	}
+
+	tracker.OnNewShoe(discards, nil)
+	if tracker.slugs != nil {
+		t.Errorf("slugs = %v without a traceable shuffle, want none", tracker.slugs)
+	}
+}
+
+func TestValidateShuffleTrackingSlugCount(t *testing.T) {
+	tests := []struct {
+		config  ShuffleTrackingConfig
+		wantErr bool
+	}{
+		{ShuffleTrackingConfig{SlugCount: -4, BetMultiplier: 3}, false},
+		{ShuffleTrackingConfig{BetMultiplier: 3}, true},
+		{ShuffleTrackingConfig{SlugCount: 2, BetMultiplier: 3}, true},
+	}
+	for _, tt := range tests {
+		if got := HasValidationErrors(validateShuffleTracking(&tt.config)); got != tt.wantErr {
+			t.Errorf("validateShuffleTracking(%+v) errors %v, want %v", tt.config, got, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package engine
+
+import (
+	"fmt"
+	"sort"
+)
+
+// ShuffleTrackingConfig, strateji dosyasındaki "shuffle_tracking" bölümüdür. Yeni shoe
+// karıştırılırken önceki shoe'nun discard sırası dilimlere ayrılır; Hi-Lo toplamı SlugCount
+// veya altında olan (yüksek kartların yoğun çıktığı) dilimler slug kabul edilir. Slug'ın kartları
+// karıştırmanın permütasyonuyla yeni shoe'daki konumlarına taşınır ve o bölge dağıtılırken bahis
+// BetMultiplier ile çarpılır.
+type ShuffleTrackingConfig struct {
+	SegmentCards  int     `json:"segment_cards"` // dilim boyutu (varsayılan 52)
+	SlugCount     float64 `json:"slug_count"`    // ör. -4: dilimdeki Hi-Lo toplamı <= -4 ise slug (zorunlu, negatif)
+	BetMultiplier float64 `json:"bet_multiplier"`
+}
+
+// slugCoverage, slug bölgesi hesaplanırken dilimin iki ucundan atılan kart oranıdır; riffle'ların
+// uzağa savurduğu birkaç kart bölgeyi tüm shoe'ya yaymasın diye kartların ortadaki %80'i alınır
+const slugCoverage = 0.1
+
+// shuffleTracker, tahmin edilen slug konumlarını tutar (shoe pozisyonu, [start, end))
+type shuffleTracker struct {
+	config *ShuffleTrackingConfig
+	slugs  [][2]int
+}
+
+// OnNewShoe, slug dilimlerini bulur ve kartlarının yeni konumlarından slug bölgelerini çıkarır.
+// Karıştırma izlenemiyorsa (newPositions nil) slug tahmini yapılmaz.
+func (t *shuffleTracker) OnNewShoe(previousDiscards []Card, newPositions []int) {
+	size := t.config.SegmentCards
+	if size <= 0 {
+		size = 52
+	}
+	t.slugs = nil
+	if newPositions == nil {
+		return
+	}
+	for start := 0; start < len(previousDiscards); start += size {
+		end := min(start+size, len(previousDiscards))
+		sum := 0
+		for _, c := range previousDiscards[start:end] {
+			sum += getHiLoValue(c)
+		}
+		if float64(sum) <= t.config.SlugCount {
+			t.slugs = append(t.slugs, slugRegion(newPositions[start:end]))
+		}
+	}
+}
+
+// slugRegion, dilimin kartlarının yeni konumlarından iki uçtaki slugCoverage oranını atarak
+// kalan kartları kapsayan [start, end) bölgesini döndürür
+func slugRegion(positions []int) [2]int {
+	sorted := append([]int{}, positions...)
+	sort.Ints(sorted)
+	drop := int(float64(len(sorted)) * slugCoverage)
+	return [2]int{sorted[drop], sorted[len(sorted)-1-drop] + 1}
+}
+
+func (t *shuffleTracker) OnCardDealt(c Card)           {}
+func (t *shuffleTracker) OnShuffle()                   {}
+func (t *shuffleTracker) OnCardsReturned(cards []Card) {}
+
+// inSlug, shoe'da bir sonraki dağıtılacak kartın tahmini bir slug içinde olup olmadığını döndürür
+func (t *shuffleTracker) inSlug(position int) bool {
+	for _, slug := range t.slugs {
+		if position >= slug[0] && position < slug[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// slugBetMultiplier, round tahmini bir slug'da başlıyorsa shuffle tracking bahis çarpanını döndürür
+func (s *CountingStrategy) slugBetMultiplier() float64 {
+	if s.tracker == nil || s.Deck == nil || !s.tracker.inSlug(s.Deck.DrawnThisShoe) {
+		return 1
+	}
+	return s.ShuffleTracking.BetMultiplier
+}
+
+func validateShuffleTracking(c *ShuffleTrackingConfig) []ValidationIssue {
+	issues := []ValidationIssue{}
+	if c == nil {
+		return issues
+	}
+	if c.SegmentCards < 0 {
+		issues = append(issues, ValidationIssue{Severity: SeverityError, Section: "shuffle_tracking", Message: fmt.Sprintf("segment_cards must not be negative, got %d", c.SegmentCards)})
+	}
+	if c.BetMultiplier <= 0 {
+		issues = append(issues, ValidationIssue{Severity: SeverityError, Section: "shuffle_tracking", Message: "bet_multiplier must be positive"})
+	}
+	// Yazılmayan slug_count 0 olur ve dilimlerin yaklaşık yarısını slug sayar
+	if c.SlugCount >= 0 {
+		issues = append(issues, ValidationIssue{Severity: SeverityError, Section: "shuffle_tracking", Message: fmt.Sprintf("slug_count must be negative (a high-card slug's Hi-Lo total), got %g", c.SlugCount)})
+	}
+	return issues
+}
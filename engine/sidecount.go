@@ -0,0 +1,119 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SideCountConfig, strateji dosyasındaki "side_counts" listesinin bir elemanıdır. Ana sayımdan
+// ayrı olarak bir rankın shoe'da kaç kez görüldüğü tutulur (ör. Hi-Opt I ve Omega II için aslar).
+// Rank "10" tüm onluk kartları (10, J, Q, K) kapsar.
+//
+// Kalan destelere göre beklenenden fazla (zengin) ya da eksik kalan her kart için running count,
+// bahis ve side_count_adjusted sapmalarda BetWeight, sigortada InsuranceWeight kadar düzeltilir.
+type SideCountConfig struct {
+	Rank            string  `json:"rank"`
+	BetWeight       float64 `json:"bet_weight"`
+	InsuranceWeight float64 `json:"insurance_weight"`
+}
+
+// sideCounter, yapılandırılan rankların shoe'da görülme sayılarını tutar
+type sideCounter struct {
+	configs []SideCountConfig
+	seen    []int
+}
+
+func newSideCounter(configs []SideCountConfig) *sideCounter {
+	return &sideCounter{configs: configs, seen: make([]int, len(configs))}
+}
+
+func (c *sideCounter) OnCardDealt(card Card) {
+	rank := sideCountRank(card.Rank)
+	for i, cfg := range c.configs {
+		if cfg.Rank == rank {
+			c.seen[i]++
+		}
+	}
+}
+
+func (c *sideCounter) OnShuffle() {
+	for i := range c.seen {
+		c.seen[i] = 0
+	}
+}
+
+func (c *sideCounter) OnCardsReturned(cards []Card) {
+	for _, card := range cards {
+		rank := sideCountRank(card.Rank)
+		for i, cfg := range c.configs {
+			if cfg.Rank == rank {
+				c.seen[i]--
+			}
+		}
+	}
+}
+
+// adjustment, zengin/eksik kartlar için running count düzeltmesini döndürür.
+// insurance true ise InsuranceWeight, değilse BetWeight kullanılır.
+func (c *sideCounter) adjustment(deck *Deck, insurance bool) float64 {
+	adj := 0.0
+	for i, cfg := range c.configs {
+		weight := cfg.BetWeight
+		if insurance {
+			weight = cfg.InsuranceWeight
+		}
+		if weight != 0 {
+			adj += weight * c.surplus(i, deck)
+		}
+	}
+	return adj
+}
+
+// surplus, i. rankın kalan kartlarda beklenen sayıdan fazlasıdır (eksikse negatif)
+func (c *sideCounter) surplus(i int, deck *Deck) float64 {
+	perDeck := 4.0
+	if c.configs[i].Rank == "10" {
+		perDeck = 16
+	}
+	remaining := perDeck*float64(deck.NumDecks) - float64(c.seen[i])
+	expected := perDeck * float64(len(deck.Cards)) / 52.0
+	return remaining - expected
+}
+
+// String, log için "A:12;7:9" biçiminde görülen kart sayılarını döndürür
+func (c *sideCounter) String() string {
+	parts := make([]string, len(c.configs))
+	for i, cfg := range c.configs {
+		parts[i] = fmt.Sprintf("%s:%d", cfg.Rank, c.seen[i])
+	}
+	return strings.Join(parts, ";")
+}
+
+// sideCountRank, onluk kartları "10" altında toplar
+func sideCountRank(rank string) string {
+	switch strings.ToUpper(rank) {
+	case "J", "Q", "K":
+		return "10"
+	}
+	return strings.ToUpper(rank)
+}
+
+func validateSideCounts(configs []SideCountConfig) []ValidationIssue {
+	issues := []ValidationIssue{}
+	seen := map[string]bool{}
+	for _, cfg := range configs {
+		if !isRank(cfg.Rank) {
+			issues = append(issues, ValidationIssue{Severity: SeverityError, Section: "side_counts", Key: cfg.Rank, Message: fmt.Sprintf("unknown rank %q", cfg.Rank)})
+			continue
+		}
+		rank := sideCountRank(cfg.Rank)
+		if rank != cfg.Rank {
+			issues = append(issues, ValidationIssue{Severity: SeverityError, Section: "side_counts", Key: cfg.Rank, Message: "use rank \"10\" to side count ten-valued cards"})
+		}
+		if seen[rank] {
+			issues = append(issues, ValidationIssue{Severity: SeverityError, Section: "side_counts", Key: cfg.Rank, Message: "rank is side counted more than once"})
+		}
+		seen[rank] = true
+	}
+	return issues
+}
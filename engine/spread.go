@@ -0,0 +1,138 @@
+package engine
+
+import (
+	"fmt"
+
+	"simjack/config"
+)
+
+// SpreadTier: true count >= MinCount ise oyuncu Hands kadar box'ta, her birinde
+// normal bahsin BetFraction katı kadar oynar.
+type SpreadTier struct {
+	MinCount    float64 `json:"min_count"`
+	Hands       int     `json:"hands"`
+	BetFraction float64 `json:"bet_fraction"`
+}
+
+// SpreadFor, mevcut true count için oynanacak el sayısını ve el başına bahis oranını döndürür.
+// Hiçbir kademe eşleşmezse oyuncu tek box'a çekilir.
+func (s *CountingStrategy) SpreadFor() (int, float64) {
+	trueCount := s.bettingTrueCount()
+	for i := len(s.Spread) - 1; i >= 0; i-- {
+		tier := s.Spread[i]
+		if trueCount >= tier.MinCount {
+			fraction := tier.BetFraction
+			if fraction <= 0 {
+				fraction = 1
+			}
+			return tier.Hands, fraction
+		}
+	}
+	return 1, 1
+}
+
+func validateSpread(tiers []SpreadTier) []ValidationIssue {
+	issues := []ValidationIssue{}
+	for i, tier := range tiers {
+		key := fmt.Sprintf("tier %d", i)
+		if tier.Hands < 1 || tier.Hands > 7 {
+			issues = append(issues, ValidationIssue{Severity: SeverityError, Section: "spread", Key: key, Message: fmt.Sprintf("hands must be between 1 and 7, got %d", tier.Hands)})
+		}
+		if tier.BetFraction < 0 {
+			issues = append(issues, ValidationIssue{Severity: SeverityError, Section: "spread", Key: key, Message: "bet_fraction must not be negative"})
+		}
+		if i > 0 && tier.MinCount <= tiers[i-1].MinCount {
+			issues = append(issues, ValidationIssue{Severity: SeverityError, Section: "spread", Key: key, Message: fmt.Sprintf("min_count %g is not greater than previous tier's %g", tier.MinCount, tiers[i-1].MinCount)})
+		}
+	}
+	return issues
+}
+
+// assignSpreadBoxes, round başında spread tanımlı oyuncuların kaç box'ta oynayacağını belirler.
+// Oyuncunun config'teki box'ları önce kullanılır; yetmezse masadaki boş box'lar bu round için
+// ödünç alınır. Önceki round'da ödünç verilen box'lar burada serbest bırakılır.
+func (e *Engine) assignSpreadBoxes() {
+	for i, box := range e.Boxes {
+		if box != nil && box.Borrowed {
+			e.Boxes[i] = nil
+		}
+	}
+
+	for _, p := range e.Players {
+		cs, ok := countingStrategyOf(p.Strategy)
+		if !ok || len(cs.Spread) == 0 || p.IsBusted || p.IsRetired || p.IsWatching() || len(p.Boxes) == 0 {
+			continue
+		}
+		hands, fraction := cs.SpreadFor()
+
+		for i, box := range p.Boxes {
+			box.SittingOut = i >= hands
+			box.SpreadFraction = fraction
+		}
+
+		template := p.Boxes[0]
+		for extra := 0; extra < hands-len(p.Boxes); extra++ {
+			idx := e.nearestFreeBox(p)
+			if idx < 0 {
+				break // masada boş box kalmadı
+			}
+			box := NewBoxWithConfig(config.BoxAssignment{
+				Index:   idx + 1,
+				MainBet: template.OriginalMainBet,
+			}, p)
+			box.Borrowed = true
+			box.SpreadFraction = fraction
+			if cs.Progression != nil {
+				box.Progression = p.spreadProgression(cs.Progression, extra)
+			}
+			e.Boxes[idx] = box
+		}
+	}
+}
+
+// activeBoxCount, oyuncunun bu round'da oynadığı box sayısıdır (spread ile oturmadığı box'lar hariç,
+// ödünç aldıkları dahil)
+func (e *Engine) activeBoxCount(p *Player) int {
+	n := 0
+	for _, box := range e.Boxes {
+		if box != nil && box.Player == p && !box.SittingOut {
+			n++
+		}
+	}
+	if n == 0 {
+		return 1
+	}
+	return n
+}
+
+// spreadProgression, oyuncunun n'inci ödünç box'unun progresyon durumunu döndürür. Ödünç box'lar her
+// round yeniden oluşturulduğundan durum oyuncuda tutulur; aynı sıradaki ek el aynı durumla devam eder.
+func (p *Player) spreadProgression(cfg *ProgressionConfig, n int) BettingSystem {
+	for len(p.spreadProgressions) <= n {
+		system, _ := NewBettingSystem(cfg) // config yüklemede doğrulandı
+		p.spreadProgressions = append(p.spreadProgressions, system)
+	}
+	return p.spreadProgressions[n]
+}
+
+// nearestFreeBox, oyuncunun ilk box'una en yakın boş box'ın indeksini döndürür
+func (e *Engine) nearestFreeBox(p *Player) int {
+	home := -1
+	for i, box := range e.Boxes {
+		if box == p.Boxes[0] {
+			home = i
+			break
+		}
+	}
+	if home < 0 {
+		home = 0
+	}
+	for dist := 1; dist < len(e.Boxes); dist++ {
+		for _, idx := range []int{home + dist, home - dist} {
+			if idx >= 0 && idx < len(e.Boxes) && e.Boxes[idx] == nil {
+				return idx
+			}
+		}
+	}
+	return -1
+}
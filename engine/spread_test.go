@@ -0,0 +1,50 @@
+package engine
+
+import (
+	"testing"
+
+	"simjack/config"
+)
+
+// Ödünç box'lar bakiye paylaşımına dahil edilmeli ve progresyon durumlarını round'lar arasında korumalıdır
+func TestAssignSpreadBoxesBorrowsBoxes(t *testing.T) {
+	cs := &CountingStrategy{
+		BaseStrategy: &DynamicStrategy{},
+		Spread:       []SpreadTier{{MinCount: 0, Hands: 3, BetFraction: 0.5}},
+		Progression:  &ProgressionConfig{System: SystemMartingale},
+	}
+	p := &Player{Strategy: cs}
+	home := NewBoxWithConfig(config.BoxAssignment{Index: 4, MainBet: 10}, p)
+	p.Boxes = []*Box{home}
+	e := &Engine{Players: []*Player{p}, Boxes: make([]*Box, 7)}
+	e.Boxes[3] = home
+
+	e.assignSpreadBoxes()
+	borrowed := []*Box{}
+	for i, box := range e.Boxes {
+		if box != nil && box.Borrowed {
+			if i != 2 && i != 4 {
+				t.Errorf("borrowed box at index %d, want next to the player's box", i)
+			}
+			borrowed = append(borrowed, box)
+		}
+	}
+	if len(borrowed) != 2 || e.activeBoxCount(p) != 3 {
+		t.Fatalf("got %d borrowed boxes and %d active, want 2 and 3", len(borrowed), e.activeBoxCount(p))
+	}
+	if borrowed[0].Progression == nil || borrowed[1].Progression == nil || borrowed[0].Progression == borrowed[1].Progression {
+		t.Fatalf("each borrowed box needs its own progression")
+	}
+
+	borrowed[0].Progression.Record(-1)
+	e.assignSpreadBoxes()
+	var again *Box
+	for _, box := range e.Boxes {
+		if box != nil && box.Borrowed && box.Progression == borrowed[0].Progression {
+			again = box
+		}
+	}
+	if again == nil || again == borrowed[0] || again.Progression.NextUnits() != 2 {
+		t.Errorf("the first extra hand should keep its progression state in the next spread round")
+	}
+}
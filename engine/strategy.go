@@ -21,15 +21,47 @@ func SetStrategyDirectory(path string) error {
 // Strategy interface - oyuncuya atanacak stratejiler bunu implement etmeli
 type Strategy interface {
 	// GetAction, eylem listesini, fallback olup olmadığını, deviation olup olmadığını ve strateji anahtarını döndürür.
-	GetAction(hand *Hand, dealerUp Card) (actions []string, isFallback bool, isDeviation bool, key string)
+	// ctx; el, dealer açık kartı, görülebilen kartlar, yasal aksiyonlar ve sayım durumunu içerir.
+	GetAction(ctx *DecisionContext) (actions []string, isFallback bool, isDeviation bool, key string)
 	DecideInsurance() bool
 	String() string
 }
 
+// BetSizer, round bahsini kendisi hesaplayan stratejilerdir (CountingStrategy, ScriptStrategy)
+type BetSizer interface {
+	GetBetUnit(base float64, bankroll float64) float64
+}
+
+// VisibleCards, karar anında oyuncunun kendi eli ve dealer açık kartı dışında görebildiği kartlardır.
+// Kompozisyona bağlı ve "third base" stratejiler bunları kullanabilir.
+type VisibleCards struct {
+	HoleCard       *Card    // oyuncu dealer'ın hole card'ını gördüyse
+	OtherHands     [][]Card // masadaki diğer eller (diğer box'lar ve aynı box'taki split elleri), o anki halleriyle
+	Burned         []Card   // bu shoe'da açık yakılan kartlar
+	PreviousRounds []Card   // bu shoe'da önceki round'larda dağıtılan kartlar
+}
+
+// All, görünen tüm kartları tek listede döndürür
+func (v *VisibleCards) All() []Card {
+	if v == nil {
+		return nil
+	}
+	cards := []Card{}
+	if v.HoleCard != nil {
+		cards = append(cards, *v.HoleCard)
+	}
+	for _, h := range v.OtherHands {
+		cards = append(cards, h...)
+	}
+	cards = append(cards, v.Burned...)
+	return append(cards, v.PreviousRounds...)
+}
+
 // Deviation: count'a göre farklı aksiyon
 type DeviationRule struct {
-	AtCount int    `json:"at_count"`
-	Action  string `json:"action"`
+	AtCount           int    `json:"at_count"`
+	Action            string `json:"action"`
+	SideCountAdjusted bool   `json:"side_count_adjusted,omitempty"` // true ise side count ile düzeltilmiş (bahis) true count kullanılır
 }
 
 // Bahis rampası: count >= MinCount ise BetUnit kullanılır
@@ -47,45 +79,112 @@ type CountingStrategy struct {
 	Deck            *Deck                    `json:"-"` // runtime'da atanır
 	CountingEnabled bool                     // 💡 yeni alan
 	Name            string 
+	Betting         *BettingConfig           // nil ise klasik bet_ramp kullanılır
+	Progression     *ProgressionConfig       // nil değilse her box kendi progresyon durumunu tutar
+	Spread          []SpreadTier             // count'a göre oynanacak box sayısı
+	TrueCount       *TrueCountPolicy         // nil ise running count kalan destelere tam bölünür
+	CountingErrors  *CountingErrorModel      // nil ise count kusursuz okunur
+	SideCounts      []SideCountConfig        // ana sayımdan ayrı izlenen ranklar
+	sideCounter     *sideCounter
+	ShuffleTracking *ShuffleTrackingConfig   // önceki shoe'nun discard sırasından slug tahmini
+	tracker         *shuffleTracker
+	KeyCards        *KeyCardConfig           // ace sequencing / key card tahminleri
+	HoleCardActions map[string][]string      // hole card görüldüğünde dealer elinin tamamına göre aksiyonlar
+	CardCountKeys   bool                     // kart sayısına özel anahtarlar (hard_16_3cards_vs_10) önce aranır
+	keyTracker      *keyCardTracker
+	dealOffset      int                      // bahsi hesaplanan box'tan önce bu round kart alacak box sayısı
+	holeCardDealt   bool                     // dealer hole card alıyor; sigorta anında son dağıtılan kart odur
+	counter         *humanCounter            // CountingErrors varsa shoe'yu izleyen hatalı sayıcı
 }
 
-func (s *CountingStrategy) GetAction(hand *Hand, dealerUp Card) ([]string, bool, bool, string) {
-	val := hand.CalculateValue()
-	dealerKey := getDealerRankKey(dealerUp)
-	key := ""
-	if hand.CanSplit() {
-		key = fmt.Sprintf("pair_%s_vs_%s", hand.Cards[0].Rank, dealerKey)
-	} else if hasAceButNotPair(hand) {
-		key = fmt.Sprintf("soft_%d_vs_%s", val, dealerKey)
-	} else {
-		key = fmt.Sprintf("hard_%d_vs_%s", val, dealerKey)
+// GetAction, elin aksiyonlarını döndürür. Listedeki aksiyonların bu elde uygulanıp uygulanamayacağına
+// (ör. üç kartla double) engine DecisionContext'teki kurallara göre karar verir.
+func (s *CountingStrategy) GetAction(ctx *DecisionContext) ([]string, bool, bool, string) {
+	hand, dealerUp, visible := ctx.Hand, ctx.DealerUp, ctx.Visible
+	if visible != nil && visible.HoleCard != nil {
+		// Hole card görüldüyse dealer elinin tamamına göre tanımlı aksiyon her şeyden önce gelir
+		holeKey := holeCardKey(hand, dealerUp, *visible.HoleCard)
+		if actions, ok := s.HoleCardActions[holeKey]; ok {
+			return actions, false, false, holeKey
+		}
+	}
+
+	chain := s.keyChain(ctx)
+	key := chain[len(chain)-1]
+	for _, k := range chain {
+		if _, ok := s.BaseStrategy.Actions[k]; ok {
+			key = k
+			break
+		}
 	}
 
-	if dev, ok := s.Deviations[key]; ok && s.Deck != nil && s.getTrueCount() >= float64(dev.AtCount) {
-		// Deviation (sapma) varsa, bu tek ve öncelikli eylemdir.
-		return []string{dev.Action}, false, true, key
+	// Sapmalar da aynı zincirde aranır; böylece after_split_* ya da kart sayısına özel bir aksiyon
+	// anahtarı normal anahtarın sapmasını gizlemez. Zincirdeki ilk sapma geçerlidir.
+	for _, k := range chain {
+		if dev, ok := s.Deviations[k]; ok {
+			if s.Deck != nil && s.deviationTrueCount(dev) >= float64(dev.AtCount) {
+				// Deviation (sapma) varsa, bu tek ve öncelikli eylemdir.
+				return []string{dev.Action}, false, true, k
+			}
+			break
+		}
 	}
 	
 	// Sapma yoksa, temel stratejiyi çağır.
-	actions, isFallback := s.BaseStrategy.GetAction(hand, dealerUp)
+	actions, isFallback := s.BaseStrategy.actionsFor(key)
 	return actions, isFallback, false, key
 }
 
+// keyChain, el için anahtarların aranma sırasıdır; son eleman normal anahtardır. Split'ten gelen
+// ellerde ad alanlı anahtarlar (after_split_no_resplit, after_split_no_das, after_split sırasıyla)
+// normal anahtardan önce gelir. CardCountKeys açıksa her ad alanında kart sayısına özel anahtar
+// (ör. "hard_16_3cards_vs_10") genel anahtardan önce denenir.
+func (s *CountingStrategy) keyChain(ctx *DecisionContext) []string {
+	cards := 0
+	if s.CardCountKeys {
+		cards = len(ctx.Hand.Cards)
+	}
+	chain := []string{}
+	for _, k := range keyCandidates(handKey(ctx.Hand, ctx.DealerUp), splitNamespaces(ctx), cards) {
+		chain = append(chain, k.String())
+	}
+	return chain
+}
+
 func (s *CountingStrategy) DecideInsurance() bool {
+	// Hole card alınmayan oyunlarda dealer'ın ikinci kartı oyuncu aksiyonlarından sonra gelir, tahmin edilemez
+	if s.keyTracker != nil && s.KeyCards.InsureOnTen && s.holeCardDealt && s.keyTracker.predictedLast("10") {
+		return true // hole card için onluk tahmini var
+	}
 	if s.Deck != nil {
-		trueCount := int(float64(s.Deck.GetRunningCount()) / (float64(len(s.Deck.Cards)) / 52.0))
-		if trueCount >= 3 {
+		if s.insuranceTrueCount() >= 3 {
 			return true // kart sayan oyuncu için TC >= 3'te insurance alınır
 		}
 	}
 	return s.AcceptInsurance // kart saymıyorsa ya da deck atanmadıysa config'teki davranışı uygula
 }
 
-func (s *CountingStrategy) GetBetUnit(base float64) float64 {
+// GetBetUnit, box'ın config bahsi (base) ve oyuncunun bu box'a düşen bakiyesiyle (bankroll) round bahsini hesaplar.
+// Masa limitleri (MinBet/MaxBet) engine tarafından ayrıca uygulanır.
+func (s *CountingStrategy) GetBetUnit(base float64, bankroll float64) float64 {
+	return s.Betting.round(s.countingBet(base, bankroll) * s.slugBetMultiplier() * s.keyCardBetMultiplier())
+}
+
+// countingBet, bahis modu ve bet ramp ile yuvarlanmamış round bahsini hesaplar
+func (s *CountingStrategy) countingBet(base float64, bankroll float64) float64 {
+	trueCount := s.bettingTrueCount()
+	switch s.Betting.mode() {
+	case BetModeKelly:
+		return s.Betting.kellyBet(base, bankroll, trueCount)
+	case BetModeBankrollPercent:
+		base = bankroll * s.Betting.BankrollPercent
+	default:
+		base = s.Betting.resizeUnit(base, bankroll)
+	}
+
 	if s.Deck == nil {
 		return base
 	}
-	trueCount := s.getTrueCount()
 	for i := len(s.BetRamp) - 1; i >= 0; i-- {
 		if trueCount >= float64(s.BetRamp[i].MinCount) {
 			return base * s.BetRamp[i].BetUnit
@@ -96,42 +195,70 @@ func (s *CountingStrategy) GetBetUnit(base float64) float64 {
 
 // JSON formatına uygun geçici yapı
 type CountingStrategyFile struct {
+	Extends         string                   `json:"extends,omitempty"`  // temel alınan strateji
+	Overlays        []string                 `json:"overlays,omitempty"` // temelin üstüne sırayla uygulanan stratejiler
 	Fallback        string                   `json:"fallback"`
 	Actions         map[string][]string      `json:"actions"`
 	Deviations      map[string]DeviationRule `json:"deviations"`
 	BetRamp         []BetRampTier            `json:"bet_ramp"`
 	CountingEnabled bool                     `json:"counting_enabled"`
 	AcceptInsurance  bool                     `json:"decide_insurance"`
+	Betting         *BettingConfig           `json:"betting,omitempty"`
+	Progression     *ProgressionConfig       `json:"progression,omitempty"`
+	Spread          []SpreadTier             `json:"spread,omitempty"`
+	TrueCount       *TrueCountPolicy         `json:"true_count,omitempty"`
+	CountingErrors  *CountingErrorModel      `json:"counting_errors,omitempty"`
+	SideCounts      []SideCountConfig        `json:"side_counts,omitempty"`
+	ShuffleTracking *ShuffleTrackingConfig   `json:"shuffle_tracking,omitempty"`
+	KeyCards        *KeyCardConfig           `json:"key_cards,omitempty"`
+	HoleCardActions map[string][]string      `json:"hole_card_actions,omitempty"`
+	Script          *ScriptConfig            `json:"script,omitempty"`
+	CardCountKeys   bool                     `json:"card_count_keys,omitempty"`
+	present         map[string]bool          // dosyada yazılmış alanlar (kalıtımda birleştirme için)
 }
 
-// JSON dosyasından CountingStrategy yükler
-func LoadCountingStrategyFromFile(name string) (*CountingStrategy, error) {
-	path := filepath.Join(strategyDirectory, fmt.Sprintf("%s.json", name))
-	file, err := os.Open(path)
+// Strateji dizininden strateji dosyasını okur; extends ve overlays çözülmüş olarak döner
+func ReadCountingStrategyFile(name string) (CountingStrategyFile, error) {
+	data, err := readStrategyFile(name)
 	if err != nil {
-		return nil, fmt.Errorf("failed to load strategy file: %w", err)
+		return data, err
 	}
-	defer file.Close()
+	return ResolveStrategyFile(name, data)
+}
 
-	decoder := json.NewDecoder(file)
+// Strateji dizininden ham strateji dosyasını okur
+func readStrategyFile(name string) (CountingStrategyFile, error) {
 	var data CountingStrategyFile
-	if err := decoder.Decode(&data); err != nil {
-		return nil, fmt.Errorf("failed to decode strategy: %w", err)
+	raw, err := os.ReadFile(StrategyFilePath(name))
+	if err != nil {
+		return data, fmt.Errorf("failed to load strategy file: %w", err)
 	}
-
-	base := &DynamicStrategy{
-		Fallback:        data.Fallback,
-		Actions:         data.Actions,
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return data, fmt.Errorf("failed to decode strategy: %w", err)
 	}
+	// Tekrar eden anahtarlar çözümlemede kaybolduğu için ham veri üzerinde kontrol edilir
+	dups, err := findDuplicateKeys(raw)
+	if err != nil {
+		return data, fmt.Errorf("failed to decode strategy: %w", err)
+	}
+	if err := validationError(dups); err != nil {
+		return data, fmt.Errorf("invalid strategy %s: %w", name, err)
+	}
+	return data, nil
+}
 
-	return &CountingStrategy{
-		BaseStrategy:    base,
-		Deviations:      data.Deviations,
-		BetRamp:         data.BetRamp,
-		Deck:            nil, // deck dışarıdan yalnızca aktifse atanır
-		CountingEnabled: data.CountingEnabled,
-		Name:            name,
-	}, nil
+// Strateji adının strateji dizinindeki dosya yolunu döndürür
+func StrategyFilePath(name string) string {
+	return filepath.Join(strategyDirectory, fmt.Sprintf("%s.json", name))
+}
+
+// JSON dosyasından CountingStrategy yükler
+func LoadCountingStrategyFromFile(name string) (*CountingStrategy, error) {
+	data, err := ReadCountingStrategyFile(name)
+	if err != nil {
+		return nil, err
+	}
+	return LoadCountingStrategyFromData(name, data)
 }
 
 // Uyum için eski fonksiyon ismi korunur
@@ -142,18 +269,11 @@ type DynamicStrategy struct {
 }
 
 func (s *DynamicStrategy) GetAction(hand *Hand, dealerUp Card) ([]string, bool) {
-	val := hand.CalculateValue()
-	dealerKey := getDealerRankKey(dealerUp)
-	// ... (key oluşturma mantığı aynı)
-	key := ""
-	if hand.CanSplit() {
-		key = fmt.Sprintf("pair_%s_vs_%s", hand.Cards[0].Rank, dealerKey)
-	} else if hasAceButNotPair(hand) {
-		key = fmt.Sprintf("soft_%d_vs_%s", val, dealerKey)
-	} else {
-		key = fmt.Sprintf("hard_%d_vs_%s", val, dealerKey)
-	}
+	return s.actionsFor(strategyKey(hand, dealerUp))
+}
 
+// actionsFor, anahtarın aksiyonlarını, anahtar yoksa fallback'i döndürür
+func (s *DynamicStrategy) actionsFor(key string) ([]string, bool) {
 	if actions, ok := s.Actions[key]; ok && len(actions) > 0 {
 		return actions, false // Ana strateji, fallback değil
 	}
@@ -165,20 +285,29 @@ func (s *DynamicStrategy) DecideInsurance() bool {
 }
 
 func LoadStrategyFromFile(name string) (Strategy, error) {
-	return LoadCountingStrategyFromFile(name)
+	data, err := ReadCountingStrategyFile(name)
+	if err != nil {
+		return nil, err
+	}
+	return LoadStrategyFromData(name, data)
 }
 
-func hasAceButNotPair(h *Hand) bool {
-	// Sadece iki kartlı eller soft olabilir (split sonrası gelenler dahil)
-	if len(h.Cards) != 2 {
-		return false
+// LoadStrategyFromData, "script" bölümü varsa counting stratejisini ScriptStrategy ile sarar.
+// data extends/overlays çözülmüş olmalıdır (ReadCountingStrategyFile, ResolveStrategyFileFrom).
+func LoadStrategyFromData(name string, data CountingStrategyFile) (Strategy, error) {
+	if err := checkStrategyData(name, data); err != nil {
+		return nil, err
 	}
-	// Eğer kartlar aynıysa (çift ise), soft değildir. AA çifti split'e girer.
-	if h.Cards[0].Rank == h.Cards[1].Rank {
-		return false
+	return newStrategy(name, data)
+}
+
+// newStrategy, checkStrategyData'dan geçmiş dosyadan doğrulamayı tekrarlamadan yeni bir strateji oluşturur
+func newStrategy(name string, data CountingStrategyFile) (Strategy, error) {
+	cs := newCountingStrategy(name, data)
+	if data.Script == nil {
+		return cs, nil
 	}
-	// Kartlardan biri As ise, el soft'tur.
-	return h.Cards[0].Rank == "A" || h.Cards[1].Rank == "A"
+	return NewScriptStrategy(cs, data.Script)
 }
 
 func getDealerRankKey(card Card) string {
@@ -194,18 +323,99 @@ func (s *CountingStrategy) String() string {
 	return s.Name
 }
 
+// getTrueCount, oyun kararları için side count düzeltmesi olmayan true count'tur
 func (s *CountingStrategy) getTrueCount() float64 {
-	if s.Deck == nil || len(s.Deck.Cards) == 0 {
-		return 0
+	return s.adjustedTrueCount(0)
+}
+
+// bettingTrueCount, bahis, spread ve wonging için side count'un bet_weight düzeltmesini içerir
+func (s *CountingStrategy) bettingTrueCount() float64 {
+	if s.sideCounter == nil || s.Deck == nil {
+		return s.getTrueCount()
+	}
+	return s.adjustedTrueCount(s.sideCounter.adjustment(s.Deck, false))
+}
+
+func (s *CountingStrategy) insuranceTrueCount() float64 {
+	if s.sideCounter == nil || s.Deck == nil {
+		return s.getTrueCount()
 	}
-	remainingDecks := float64(len(s.Deck.Cards)) / 52.0
-	if remainingDecks == 0 {
+	return s.adjustedTrueCount(s.sideCounter.adjustment(s.Deck, true))
+}
+
+func (s *CountingStrategy) deviationTrueCount(dev DeviationRule) float64 {
+	if dev.SideCountAdjusted {
+		return s.bettingTrueCount()
+	}
+	return s.getTrueCount()
+}
+
+// adjustedTrueCount, running count'a adj eklenmiş halini true count politikasıyla çevirir
+func (s *CountingStrategy) adjustedTrueCount(adj float64) float64 {
+	if s.Deck == nil {
 		return 0
 	}
-	return float64(s.Deck.RunningCount) / remainingDecks
+	if s.counter == nil {
+		return s.TrueCount.TrueCount(float64(s.Deck.RunningCount)+adj, s.Deck)
+	}
+
+	// Hata modeli: sayıcının kendi running count'u; deste tahmini ve yuvarlama hata modelinde
+	// tanımlıysa true_count politikasının yerine geçer
+	policy := s.TrueCount.withOverrides(s.CountingErrors.DeckEstimation, s.CountingErrors.TCRounding)
+	return policy.TrueCount(float64(s.counter.RunningCount)+adj, s.Deck)
+}
+
+// SideCountState, log için side count değerlerini döndürür (side count yoksa boş)
+func (s *CountingStrategy) SideCountState() string {
+	if s.sideCounter == nil {
+		return ""
+	}
+	return s.sideCounter.String()
 }
 
+// AttachDeck, stratejiyi shoe'ya bağlar. Hata modeli tanımlıysa sayıcı shoe'yu izlemeye başlar.
+func (s *CountingStrategy) AttachDeck(deck *Deck) {
+	s.Deck = deck
+	if s.CountingErrors != nil {
+		s.counter = &humanCounter{model: s.CountingErrors}
+		deck.AddObserver(s.counter)
+	}
+	if len(s.SideCounts) > 0 {
+		s.sideCounter = newSideCounter(s.SideCounts)
+		deck.AddObserver(s.sideCounter)
+	}
+	if s.ShuffleTracking != nil {
+		s.tracker = &shuffleTracker{config: s.ShuffleTracking}
+		deck.AddObserver(s.tracker)
+	}
+	if s.KeyCards != nil {
+		s.keyTracker = newKeyCardTracker(s.KeyCards, deck)
+		deck.AddObserver(s.keyTracker)
+	}
+}
+
+// LoadCountingStrategyFromData, çözülmüş strateji dosyasından CountingStrategy oluşturur.
+// extends/overlays çözülmemişse hata döner; kalıtım yüklemeden önce bir kez çözülür.
 func LoadCountingStrategyFromData(name string, data CountingStrategyFile) (*CountingStrategy, error) {
+	if err := checkStrategyData(name, data); err != nil {
+		return nil, err
+	}
+	return newCountingStrategy(name, data), nil
+}
+
+// checkStrategyData, çözülmemiş kalıtımı ve sessizce Fallback'e düşecek hatalı anahtar/aksiyonları reddeder
+func checkStrategyData(name string, data CountingStrategyFile) error {
+	if data.Extends != "" || len(data.Overlays) > 0 {
+		return fmt.Errorf("strategy %s: extends/overlays must be resolved before loading", name)
+	}
+	if err := validationError(ValidateCountingStrategy(data)); err != nil {
+		return fmt.Errorf("invalid strategy %s: %w", name, err)
+	}
+	return nil
+}
+
+// newCountingStrategy, doğrulanmış dosyadan desteye bağlanmamış yeni bir CountingStrategy oluşturur
+func newCountingStrategy(name string, data CountingStrategyFile) *CountingStrategy {
 	base := &DynamicStrategy{
 		Fallback:        data.Fallback,
 		Actions:         data.Actions,
@@ -219,5 +429,15 @@ func LoadCountingStrategyFromData(name string, data CountingStrategyFile) (*Coun
 		Deck:            nil,
 		CountingEnabled: data.CountingEnabled,
 		Name:            name,
-	}, nil
+		Betting:         data.Betting,
+		Progression:     data.Progression,
+		Spread:          data.Spread,
+		TrueCount:       data.TrueCount,
+		CountingErrors:  data.CountingErrors,
+		SideCounts:      data.SideCounts,
+		ShuffleTracking: data.ShuffleTracking,
+		KeyCards:        data.KeyCards,
+		HoleCardActions: data.HoleCardActions,
+		CardCountKeys:   data.CardCountKeys,
+	}
 }
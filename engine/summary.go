@@ -0,0 +1,56 @@
+package engine
+
+import "fmt"
+
+// PlayerSummary, simülasyon sonunda bir oyuncu için özet istatistiklerdir.
+// Saatlik kazanç, izlenen round'lar dahil masada geçen süreye göre hesaplanır (wonging).
+type PlayerSummary struct {
+	PlayerID        int     `json:"player_id"`
+	Owner           string  `json:"owner"`
+	Strategy        string  `json:"strategy"`
+	InitialBalance  float64 `json:"initial_balance"`
+	FinalBalance    float64 `json:"final_balance"`
+	Net             float64 `json:"net"`
+	RoundsPlayed    int     `json:"rounds_played"`
+	RoundsWatched   int     `json:"rounds_watched"`
+	Hours           float64 `json:"hours"`
+	WinPerHour      float64 `json:"win_per_hour"`
+	WinPer100Rounds float64 `json:"win_per_100_rounds_played"`
+	BustedAtRound   int     `json:"busted_at_round"`
+	RetiredAtRound  int     `json:"retired_at_round"`
+}
+
+// Summary, her oyuncu için özet istatistikleri döndürür
+func (e *Engine) Summary() []PlayerSummary {
+	summaries := []PlayerSummary{}
+	for _, p := range e.Players {
+		s := PlayerSummary{
+			PlayerID:       p.ID,
+			Owner:          p.Owner,
+			Strategy:       p.Strategy.String(),
+			InitialBalance: p.InitialBalance,
+			FinalBalance:   p.Balance,
+			Net:            p.Balance - p.InitialBalance,
+			RoundsPlayed:   p.RoundsPlayed,
+			RoundsWatched:  p.RoundsWatched,
+			BustedAtRound:  p.BustedAtRound,
+			RetiredAtRound: p.RetiredAtRound,
+		}
+		if e.RoundsPerHour > 0 {
+			s.Hours = float64(p.RoundsPlayed+p.RoundsWatched) / e.RoundsPerHour
+		}
+		if s.Hours > 0 {
+			s.WinPerHour = s.Net / s.Hours
+		}
+		if p.RoundsPlayed > 0 {
+			s.WinPer100Rounds = s.Net / float64(p.RoundsPlayed) * 100
+		}
+		summaries = append(summaries, s)
+	}
+	return summaries
+}
+
+func (s PlayerSummary) String() string {
+	return fmt.Sprintf("Player %d (%s, %s) | Net: %.2f | Played: %d Watched: %d | %.2f/hour over %.1f hours | %.2f per 100 rounds",
+		s.PlayerID, s.Owner, s.Strategy, s.Net, s.RoundsPlayed, s.RoundsWatched, s.WinPerHour, s.Hours, s.WinPer100Rounds)
+}
@@ -0,0 +1,150 @@
+package engine
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// Kalan deste tahmininin çözünürlüğü
+const (
+	DeckEstimationExact       = "exact"
+	DeckEstimationHalf        = "half"         // en yakın yarım desteye yuvarlanır
+	DeckEstimationWhole       = "whole"        // en yakın tam desteye yuvarlanır
+	DeckEstimationDiscardTray = "discard_tray" // discard tray'deki kartlar yarım desteye yuvarlanıp toplamdan düşülür
+)
+
+// True count'un tam sayıya çevrilme biçimi
+const (
+	TCRoundingExact    = "exact"
+	TCRoundingFloor    = "floor"    // -1.5 -> -2
+	TCRoundingTruncate = "truncate" // -1.5 -> -1
+	TCRoundingRound    = "round"    // -1.5 -> -2, 1.4 -> 1
+)
+
+// TrueCountPolicy, strateji dosyasındaki "true_count" bölümüdür. Running count'un true count'a
+// nasıl çevrileceğini belirler; bet ramp, sapmalar, sigorta, spread, wonging ve log'daki
+// true_count sütunu aynı değeri kullanır. Boş alanlar "exact" kabul edilir.
+type TrueCountPolicy struct {
+	Rounding       string `json:"rounding"`        // exact, floor, truncate, round
+	DeckEstimation string `json:"deck_estimation"` // exact, half, whole, discard_tray
+}
+
+// TrueCount, verilen running count'u politikaya göre shoe'nun durumuyla true count'a çevirir.
+// nil politika kalan destelere tam bölme yapar.
+func (p *TrueCountPolicy) TrueCount(runningCount float64, deck *Deck) float64 {
+	if deck == nil {
+		return 0
+	}
+	decks := p.remainingDecks(deck)
+	if decks <= 0 {
+		return 0
+	}
+	return p.round(runningCount / decks)
+}
+
+func (p *TrueCountPolicy) remainingDecks(deck *Deck) float64 {
+	exact := float64(len(deck.Cards)) / 52.0
+	if p == nil {
+		return exact
+	}
+	switch p.DeckEstimation {
+	case DeckEstimationHalf:
+		return math.Max(0.5, math.Round(exact*2)/2)
+	case DeckEstimationWhole:
+		return math.Max(1, math.Round(exact))
+	case DeckEstimationDiscardTray:
+		if deck.NumDecks == 0 {
+			return exact
+		}
+		discarded := math.Round(float64(deck.DrawnThisShoe)/52.0*2) / 2
+		return math.Max(0.5, float64(deck.NumDecks)-discarded)
+	}
+	return exact
+}
+
+func (p *TrueCountPolicy) round(tc float64) float64 {
+	if p == nil {
+		return tc
+	}
+	switch p.Rounding {
+	case TCRoundingFloor:
+		return math.Floor(tc)
+	case TCRoundingTruncate:
+		return math.Trunc(tc)
+	case TCRoundingRound:
+		return math.Round(tc)
+	}
+	return tc
+}
+
+// withOverrides, boş olmayan alanları politikanın üzerine yazar (ör. counting_errors)
+func (p *TrueCountPolicy) withOverrides(deckEstimation, rounding string) *TrueCountPolicy {
+	merged := TrueCountPolicy{}
+	if p != nil {
+		merged = *p
+	}
+	if deckEstimation != "" {
+		merged.DeckEstimation = deckEstimation
+	}
+	if rounding != "" {
+		merged.Rounding = rounding
+	}
+	return &merged
+}
+
+// trueCountFor, shoe'nun gerçek running count'unu oyuncunun stratejisinin true_count politikasıyla
+// true count'a çevirir. Sayma hataları dahil edilmez; böylece log'daki running count ile tutarlıdır
+// (algılanan değer perceivedCount ile ayrıca yazılır). Sayma yapmayan stratejiler için shoe'nun tam
+// true count'u kullanılır.
+func trueCountFor(s Strategy, deck *Deck) float64 {
+	if cs, ok := countingStrategyOf(s); ok && cs.Deck != nil {
+		return cs.TrueCount.TrueCount(float64(cs.Deck.RunningCount), cs.Deck)
+	}
+	if deck == nil {
+		return 0
+	}
+	return deck.TrueCount()
+}
+
+// perceivedCount, sayma hataları modellenen stratejinin kendi running count'u ve bahis ile kararlarda
+// kullandığı true count'tur; hata modeli yoksa ok false döner.
+func perceivedCount(s Strategy) (runningCount int, trueCount float64, ok bool) {
+	cs, isCounting := countingStrategyOf(s)
+	if !isCounting || cs.counter == nil || cs.Deck == nil {
+		return 0, 0, false
+	}
+	return cs.counter.RunningCount, cs.getTrueCount(), true
+}
+
+// formatTrueCount, log'un true_count sütunlarını yazar. true_count politikası tanımlamayan
+// stratejilerde sütun eskisi gibi tam sayıya (sıfıra doğru) kesilir; politika varsa
+// stratejinin gördüğü değer iki ondalıkla yazılır.
+func formatTrueCount(s Strategy, trueCount float64) string {
+	if cs, ok := countingStrategyOf(s); ok && cs.TrueCount != nil {
+		return strconv.FormatFloat(trueCount, 'f', 2, 64)
+	}
+	return strconv.Itoa(int(trueCount))
+}
+
+func validateTrueCountFields(section, deckEstimation, rounding, roundingField string) []ValidationIssue {
+	issues := []ValidationIssue{}
+	switch deckEstimation {
+	case "", DeckEstimationExact, DeckEstimationHalf, DeckEstimationWhole, DeckEstimationDiscardTray:
+	default:
+		issues = append(issues, ValidationIssue{Severity: SeverityError, Section: section, Message: fmt.Sprintf("unknown deck_estimation %q", deckEstimation)})
+	}
+	switch rounding {
+	case "", TCRoundingExact, TCRoundingFloor, TCRoundingTruncate, TCRoundingRound:
+	default:
+		issues = append(issues, ValidationIssue{Severity: SeverityError, Section: section, Message: fmt.Sprintf("unknown %s %q", roundingField, rounding)})
+	}
+	return issues
+}
+
+func validateTrueCountPolicy(p *TrueCountPolicy) []ValidationIssue {
+	if p == nil {
+		return []ValidationIssue{}
+	}
+	return validateTrueCountFields("true_count", p.DeckEstimation, p.Rounding, "rounding")
+}
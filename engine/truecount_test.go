@@ -0,0 +1,45 @@
+package engine
+
+import "testing"
+
+// Politikası olmayan stratejilerde log sütunu eski tam sayı biçimini korur
+func TestFormatTrueCount(t *testing.T) {
+	tests := []struct {
+		strategy Strategy
+		tc       float64
+		want     string
+	}{
+		{&CountingStrategy{}, 2.75, "2"},
+		{&CountingStrategy{}, -1.5, "-1"},
+		{&CountingStrategy{TrueCount: &TrueCountPolicy{Rounding: TCRoundingFloor}}, 2, "2.00"},
+		{&ScriptStrategy{CountingStrategy: &CountingStrategy{TrueCount: &TrueCountPolicy{}}}, 1.25, "1.25"},
+		{nil, 3.9, "3"},
+	}
+	for _, tt := range tests {
+		if got := formatTrueCount(tt.strategy, tt.tc); got != tt.want {
+			t.Errorf("formatTrueCount(%T, %v) = %q, want %q", tt.strategy, tt.tc, got, tt.want)
+		}
+	}
+}
+
+// Log'un true_count sütunu gerçek running count'tan hesaplanır; sayma hatası olan oyuncunun
+// algıladığı değerler ayrıca döndürülür
+func TestLoggedCountsWithCountingErrors(t *testing.T) {
+	deck := NewDeck(1, nil)
+	deck.Cards = deck.Cards[:26]
+	deck.RunningCount = 4
+	cs := &CountingStrategy{CountingErrors: &CountingErrorModel{}, TrueCount: &TrueCountPolicy{}}
+	cs.AttachDeck(deck)
+	cs.counter.RunningCount = -2
+
+	if got := trueCountFor(cs, deck); got != 8 {
+		t.Errorf("trueCountFor = %v, want 8 from the real running count", got)
+	}
+	rc, tc, ok := perceivedCount(cs)
+	if !ok || rc != -2 || tc != -4 {
+		t.Errorf("perceivedCount = %d, %v, %v; want -2, -4, true", rc, tc, ok)
+	}
+	if _, _, ok := perceivedCount(&CountingStrategy{Deck: deck}); ok {
+		t.Errorf("perceivedCount without counting errors should report nothing")
+	}
+}
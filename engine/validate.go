@@ -0,0 +1,329 @@
+package engine
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Strateji dosyalarında kullanılabilecek aksiyonlar (executeBoxActions'ın tanıdıkları)
+var KnownActions = []string{"hit", "stand", "double", "split", "surrender"}
+
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// ValidationIssue, strateji dosyasında bulunan tek bir sorunu tanımlar.
+type ValidationIssue struct {
+	Severity string `json:"severity"`
+	Section  string `json:"section"` // actions, deviations, bet_ramp, fallback
+	Key      string `json:"key,omitempty"`
+	Message  string `json:"message"`
+}
+
+func (i ValidationIssue) String() string {
+	if i.Key != "" {
+		return fmt.Sprintf("%s: %s[%s]: %s", i.Severity, i.Section, i.Key, i.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s", i.Severity, i.Section, i.Message)
+}
+
+// ReachableStrategyKeys, engine'in çalışma anında sorabileceği tüm strateji anahtarlarını döndürür.
+// Dealer J/Q/K "10" olarak eşlenir; oyuncu çiftleri ise kendi rank'ı ile (pair_J gibi) sorulur.
+// 21 ve üzeri ellerde karar sorulmadığı için bu toplamlar dahil edilmez.
+func ReachableStrategyKeys() []string {
+	keys := []string{}
+	for _, d := range DealerKeys {
+		for _, r := range Ranks {
+			keys = append(keys, StrategyKey{Kind: KeyKindPair, Player: r, Dealer: d}.String())
+		}
+		// A,A çift olduğu için soft 12 iki kartla oluşmaz
+		for t := 13; t <= 20; t++ {
+			keys = append(keys, StrategyKey{Kind: KeyKindSoft, Player: fmt.Sprint(t), Dealer: d}.String())
+		}
+		// 2,2 çift olduğu için en küçük hard toplam 5'tir
+		for t := 5; t <= 20; t++ {
+			keys = append(keys, StrategyKey{Kind: KeyKindHard, Player: fmt.Sprint(t), Dealer: d}.String())
+		}
+	}
+	return keys
+}
+
+// ValidateCountingStrategy, çalışma anında sessizce Fallback'e düşecek hataları ve eksikleri raporlar.
+func ValidateCountingStrategy(data CountingStrategyFile) []ValidationIssue {
+	issues := []ValidationIssue{}
+	add := func(severity, section, key, format string, args ...interface{}) {
+		issues = append(issues, ValidationIssue{Severity: severity, Section: section, Key: key, Message: fmt.Sprintf(format, args...)})
+	}
+
+	reachable := map[string]bool{}
+	for _, k := range ReachableStrategyKeys() {
+		reachable[k] = true
+	}
+
+	if data.Fallback == "" {
+		add(SeverityWarning, "fallback", "", "fallback is empty, hands without a matching key will stand")
+	} else if !isKnownAction(data.Fallback) {
+		add(SeverityError, "fallback", "", "unknown action %q%s", data.Fallback, suggestAction(data.Fallback))
+	}
+
+	for _, key := range sortedKeys(data.Actions) {
+		actions := data.Actions[key]
+		if k, err := ParseStrategyKey(key); err != nil {
+			add(SeverityError, "actions", key, "%v", err)
+		} else if !reachable[k.Base().String()] {
+			add(SeverityWarning, "actions", key, "key is never looked up by the engine")
+		} else if k.Cards > 0 && !data.CardCountKeys {
+			add(SeverityWarning, "actions", key, "card count key is ignored unless card_count_keys is true")
+		} else if k.Namespace == KeyNamespaceAfterSplitNoResplit && k.Kind != KeyKindPair {
+			add(SeverityWarning, "actions", key, "%s only applies to pairs", KeyNamespaceAfterSplitNoResplit)
+		}
+		if len(actions) == 0 {
+			add(SeverityWarning, "actions", key, "empty action list, fallback will be used")
+		}
+		seen := map[string]bool{}
+		for _, a := range actions {
+			if !isKnownAction(a) {
+				add(SeverityError, "actions", key, "unknown action %q%s", a, suggestAction(a))
+			} else if seen[a] {
+				add(SeverityWarning, "actions", key, "action %q listed more than once", a)
+			}
+			seen[a] = true
+		}
+	}
+
+	for _, key := range ReachableStrategyKeys() {
+		if _, ok := data.Actions[key]; !ok {
+			add(SeverityWarning, "actions", key, "missing, fallback %q will be used", data.Fallback)
+		}
+	}
+
+	for _, key := range sortedKeys(data.HoleCardActions) {
+		if _, _, _, err := ParseHoleCardKey(key); err != nil {
+			add(SeverityError, "hole_card_actions", key, "%v", err)
+		}
+		for _, a := range data.HoleCardActions[key] {
+			if !isKnownAction(a) {
+				add(SeverityError, "hole_card_actions", key, "unknown action %q%s", a, suggestAction(a))
+			}
+		}
+	}
+
+	for _, key := range sortedKeys(data.Deviations) {
+		dev := data.Deviations[key]
+		if k, err := ParseStrategyKey(key); err != nil {
+			add(SeverityError, "deviations", key, "%v", err)
+		} else if !reachable[k.Base().String()] {
+			add(SeverityWarning, "deviations", key, "key is never looked up by the engine")
+		}
+		if !isKnownAction(dev.Action) {
+			add(SeverityError, "deviations", key, "unknown action %q%s", dev.Action, suggestAction(dev.Action))
+		}
+		if resolvedActions(data.Actions, key) == nil {
+			add(SeverityWarning, "deviations", key, "deviation for a key that is not in actions")
+		}
+		if dev.SideCountAdjusted && len(data.SideCounts) == 0 {
+			add(SeverityWarning, "deviations", key, "side_count_adjusted is set but the strategy has no side_counts")
+		}
+	}
+
+	// GetBetUnit rampayı sondan başa tarar, bu yüzden MinCount artan sırada olmalıdır
+	for i := 1; i < len(data.BetRamp); i++ {
+		prev, cur := data.BetRamp[i-1], data.BetRamp[i]
+		key := fmt.Sprintf("tier %d", i)
+		if cur.MinCount <= prev.MinCount {
+			add(SeverityError, "bet_ramp", key, "min_count %d is not greater than previous tier's %d", cur.MinCount, prev.MinCount)
+		}
+		if cur.BetUnit < prev.BetUnit {
+			add(SeverityWarning, "bet_ramp", key, "bet_unit %.2f is lower than previous tier's %.2f", cur.BetUnit, prev.BetUnit)
+		}
+	}
+	for i, tier := range data.BetRamp {
+		if tier.BetUnit <= 0 {
+			add(SeverityError, "bet_ramp", fmt.Sprintf("tier %d", i), "bet_unit must be positive, got %.2f", tier.BetUnit)
+		}
+	}
+
+	issues = append(issues, validateBetting(data.Betting)...)
+	issues = append(issues, validateProgression(data.Progression)...)
+	issues = append(issues, validateSpread(data.Spread)...)
+	issues = append(issues, validateTrueCountPolicy(data.TrueCount)...)
+	issues = append(issues, validateCountingErrors(data.CountingErrors)...)
+	issues = append(issues, validateSideCounts(data.SideCounts)...)
+	issues = append(issues, validateShuffleTracking(data.ShuffleTracking)...)
+	issues = append(issues, validateKeyCards(data.KeyCards)...)
+	issues = append(issues, validateScript(data.Script)...)
+
+	// Bu bölümler desteyi izleyen gözlemcilerdir; counting_enabled false iken desteye bağlanmazlar
+	if !data.CountingEnabled {
+		sections := []struct {
+			name    string
+			present bool
+		}{
+			{"counting_errors", data.CountingErrors != nil},
+			{"side_counts", len(data.SideCounts) > 0},
+			{"shuffle_tracking", data.ShuffleTracking != nil},
+			{"key_cards", data.KeyCards != nil},
+		}
+		for _, section := range sections {
+			if section.present {
+				add(SeverityWarning, section.name, "", "ignored because counting_enabled is false")
+			}
+		}
+	}
+
+	return issues
+}
+
+// ValidateCountingStrategyJSON, ham JSON'u çözmeden önce tekrar eden anahtarları da kontrol eder.
+// encoding/json tekrar eden anahtarlarda sonuncuyu sessizce kullandığı için bu kontrol ayrıca yapılır.
+// extends/overlays varsa doğrulama birleştirilmiş strateji üzerinde yapılır.
+func ValidateCountingStrategyJSON(name string, raw []byte) ([]ValidationIssue, error) {
+	var data CountingStrategyFile
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, fmt.Errorf("failed to decode strategy: %w", err)
+	}
+	issues, err := findDuplicateKeys(raw)
+	if err != nil {
+		return nil, err
+	}
+	data, err = ResolveStrategyFile(name, data)
+	if err != nil {
+		return append(issues, ValidationIssue{Severity: SeverityError, Section: "extends", Message: err.Error()}), nil
+	}
+	return append(issues, ValidateCountingStrategy(data)...), nil
+}
+
+// HasValidationErrors, raporda hata seviyesinde sorun olup olmadığını söyler
+func HasValidationErrors(issues []ValidationIssue) bool {
+	for _, i := range issues {
+		if i.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// validationError, hata seviyesindeki sorunları tek bir error'da birleştirir
+func validationError(issues []ValidationIssue) error {
+	msgs := []string{}
+	for _, i := range issues {
+		if i.Severity == SeverityError {
+			msgs = append(msgs, i.String())
+		}
+	}
+	if len(msgs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%s", strings.Join(msgs, "; "))
+}
+
+// findDuplicateKeys, actions, deviations ve hole_card_actions nesnelerinde tekrar eden anahtarları bulur
+func findDuplicateKeys(raw []byte) ([]ValidationIssue, error) {
+	issues := []ValidationIssue{}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	if _, err := dec.Token(); err != nil { // '{'
+		return nil, err
+	}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		section, _ := tok.(string)
+		if section != "actions" && section != "deviations" && section != "hole_card_actions" {
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if t, err := dec.Token(); err != nil {
+			return nil, err
+		} else if t == nil {
+			continue // "actions": null
+		} else if d, ok := t.(json.Delim); !ok || d != '{' {
+			return nil, fmt.Errorf("%s must be an object", section)
+		}
+		seen := map[string]bool{}
+		for dec.More() {
+			t, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			key, _ := t.(string)
+			if seen[key] {
+				issues = append(issues, ValidationIssue{Severity: SeverityError, Section: section, Key: key, Message: "duplicate key, only the last value is used"})
+			}
+			seen[key] = true
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return nil, err
+			}
+		}
+		if _, err := dec.Token(); err != nil { // '}'
+			return nil, err
+		}
+	}
+	return issues, nil
+}
+
+func isKnownAction(a string) bool {
+	for _, k := range KnownActions {
+		if k == a {
+			return true
+		}
+	}
+	return false
+}
+
+// suggestAction, yazım hatası olan bir aksiyon için en yakın bilinen aksiyonu önerir
+func suggestAction(a string) string {
+	best, bestDist := "", 3
+	for _, k := range KnownActions {
+		if d := editDistance(strings.ToLower(a), k); d < bestDist {
+			best, bestDist = k, d
+		}
+	}
+	if best == "" {
+		return ""
+	}
+	return fmt.Sprintf(" (did you mean %q?)", best)
+}
+
+// editDistance, iki harfin yer değiştirmesini ("dobule") tek hata sayan düzenleme mesafesidir
+func editDistance(a, b string) int {
+	d := make([][]int, len(a)+1)
+	for i := range d {
+		d[i] = make([]int, len(b)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(a)][len(b)]
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,32 @@
+package engine
+
+import (
+	"reflect"
+	"testing"
+)
+
+// Desteyi izleyen bölümler counting_enabled false iken bağlanmaz; bunun için uyarı verilmelidir
+func TestValidateObserverSectionsNeedCounting(t *testing.T) {
+	data := CountingStrategyFile{
+		Fallback:        "stand",
+		SideCounts:      []SideCountConfig{{Rank: "A"}},
+		ShuffleTracking: &ShuffleTrackingConfig{SlugCount: -4},
+		KeyCards:        &KeyCardConfig{Targets: []string{"A"}},
+	}
+	sections := func() []string {
+		got := []string{}
+		for _, issue := range ValidateCountingStrategy(data) {
+			if issue.Message == "ignored because counting_enabled is false" {
+				got = append(got, issue.Section)
+			}
+		}
+		return got
+	}
+	if got, want := sections(), []string{"side_counts", "shuffle_tracking", "key_cards"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("warned sections = %v, want %v", got, want)
+	}
+	data.CountingEnabled = true
+	if got := sections(); len(got) != 0 {
+		t.Errorf("warned sections = %v with counting enabled, want none", got)
+	}
+}
@@ -26,6 +26,16 @@ func flagIsPassed(name string) bool {
 }
 
 func main() {
+	if len(os.Args) > 1 {
+		if cmd, ok := commands[os.Args[1]]; ok {
+			if err := cmd(os.Args[2:]); err != nil {
+				fmt.Printf("%s failed: %v\n", os.Args[1], err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
 	configPath := flag.String("config", "config.json", "Path to simulation config JSON file (default: config.json)")
 	configJSON := flag.String("config_json", "", "Inline JSON for simulation config")
 	logPath := flag.String("log", "output.csv", "Path to log output CSV file (default: output.csv)")
@@ -34,6 +44,9 @@ func main() {
 	useStdinCombined := flag.Bool("use-stdin-combined", false, "Load config + strategies from single JSON on stdin")
 	showProgress := flag.Bool("progress", false, "Show progress bar during simulation")
 	debug := flag.Bool("debug", false, "Enable debug mode for round-level output")
+	coveragePath := flag.String("coverage", "", "Write per-key strategy coverage report to this file (.json or .csv)")
+	summaryPath := flag.String("summary", "", "Write per-player summary statistics (rounds played/watched, win per hour) to this JSON file")
+	evReportPath := flag.String("ev-report", "", "Write per (key, action) realized EV report to this file (.json or .csv)")
 	help := flag.Bool("help", false, "Show usage")
 	flag.Parse()
 
@@ -120,10 +133,47 @@ func main() {
 	}
 	defer logger.Close()
 
-	eng := engine.NewEngine(cfg, logger, *showProgress, *debug, strategyBundle)
+	eng, err := engine.NewEngine(cfg, logger, *showProgress, *debug, strategyBundle)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	if *coveragePath != "" {
+		eng.Coverage = engine.NewCoverageReport()
+	}
+	if *evReportPath != "" {
+		eng.EVReport = engine.NewEVAttributionReport()
+	}
 	eng.Run()
 
+	if eng.Coverage != nil {
+		if err := eng.Coverage.WriteFile(*coveragePath); err != nil {
+			fmt.Printf("Failed to write coverage report: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if eng.EVReport != nil {
+		if err := eng.EVReport.WriteFile(*evReportPath); err != nil {
+			fmt.Printf("Failed to write EV report: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if *summaryPath != "" {
+		out, err := json.MarshalIndent(eng.Summary(), "", "  ")
+		if err == nil {
+			err = os.WriteFile(*summaryPath, out, 0644)
+		}
+		if err != nil {
+			fmt.Printf("Failed to write summary: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
 	if *debug {
+		for _, s := range eng.Summary() {
+			fmt.Println(s)
+		}
 		fmt.Println("Simulation completed. Log written to", logger.FinalPath)
 	}
 }
@@ -132,4 +182,11 @@ func printUsage() {
 	fmt.Println("SimJack - Blackjack Simulation")
 	fmt.Println("Usage:")
 	flag.PrintDefaults()
+	fmt.Println()
+	fmt.Println("Commands:")
+	fmt.Println("  indices   Generate a deviations block for a strategy by simulation (simjack indices -help)")
+	fmt.Println("  validate  Lint strategy files for unknown keys/actions, missing cells and ramp order")
+	fmt.Println("  risk      Monte Carlo risk of ruin and bankroll percentile curves per player")
+	fmt.Println("  chart     Render a strategy as a chart, or convert strategies to/from CSV/grid charts")
+	fmt.Println("  diff      Show action, deviation and bet ramp differences between two strategies")
 }